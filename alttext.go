@@ -0,0 +1,178 @@
+package twittership
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxAltTextLength is the most characters Twitter allows in the alt text of an image.
+const MaxAltTextLength = 1000
+
+// GetAltTextFromGame returns a description of the game image drawn for the player side, for
+// screen reader users. See PlayerView.AltText.
+func GetAltTextFromGame(g Game) string {
+	return g.View(PlayerSide).AltText()
+}
+
+// AltText returns a description of the game as seen by the side of the view, for screen
+// reader users. It describes the last shot and its result, the ships remaining on each side,
+// and the hits and misses on each board row by row. The description is never longer than
+// MaxAltTextLength characters, the row by row summary is shortened to fit when needed.
+func (v PlayerView) AltText() string {
+	opponent := v.Side.Opponent()
+
+	summary := fmt.Sprintf("Battleship game seen by the %s. %s", v.Side, v.lastShotText())
+	summary += fmt.Sprintf(" %s ships remaining: %d of %d.", v.Side, fleetSize-sunkCount(v.Own.Ships), fleetSize)
+	summary += fmt.Sprintf(" %s ships remaining: %d of %d.", opponent, fleetSize-sunkCount(v.Opponent.Ships), fleetSize)
+
+	if v.Finished && v.Winner != nil {
+		summary += fmt.Sprintf(" Game over, the %s won. %s.", *v.Winner, v.FinishReason)
+	}
+
+	boards := []struct {
+		name  string
+		shots []Move
+	}{
+		{fmt.Sprintf("%s board", opponent), v.Opponent.Shots},
+		{fmt.Sprintf("%s board", v.Side), v.Own.Shots},
+	}
+
+	// Try the most detailed summary first and fall back to shorter ones until it fits
+	for _, describe := range []func([]Move) string{altTextRows, altTextRowCounts, altTextTotals} {
+		text := summary
+		for _, board := range boards {
+			text += fmt.Sprintf(" %s: %s", board.name, describe(board.shots))
+		}
+
+		if utf8.RuneCountInString(text) <= MaxAltTextLength {
+			return text
+		}
+	}
+
+	return truncateRunes(summary, MaxAltTextLength)
+}
+
+func (v PlayerView) lastShotText() string {
+	var last *Move
+	for _, shots := range [][]Move{v.Own.Shots, v.Opponent.Shots} {
+		for i := range shots {
+			if last == nil || shots[i].Number > last.Number {
+				last = &shots[i]
+			}
+		}
+	}
+
+	if last == nil {
+		return "No shots have been fired."
+	}
+
+	result := "missed"
+	if last.Hit {
+		result = "hit"
+	}
+
+	if last.Sunk != "" {
+		result = fmt.Sprintf("hit and sunk the %s", last.Sunk)
+	}
+
+	return fmt.Sprintf("Last shot: the %s fired at %s and %s.", last.Side, last.Position, result)
+}
+
+func sunkCount(ships []ShipView) int {
+	count := 0
+	for _, s := range ships {
+		if s.Sunk {
+			count++
+		}
+	}
+
+	return count
+}
+
+// shotsByRow groups the hit and miss positions of shots by row, with the positions in
+// column order.
+func shotsByRow(shots []Move) ([10][]string, [10][]string) {
+	sorted := append([]Move(nil), shots...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Y != sorted[j].Y {
+			return sorted[i].Y < sorted[j].Y
+		}
+
+		return sorted[i].X < sorted[j].X
+	})
+
+	var hits, misses [10][]string
+	for _, m := range sorted {
+		if m.Hit {
+			hits[m.Y] = append(hits[m.Y], m.Position)
+		} else {
+			misses[m.Y] = append(misses[m.Y], m.Position)
+		}
+	}
+
+	return hits, misses
+}
+
+// altTextRows lists every hit and miss position row by row I.E. "Row A hits A1, A2; misses A5."
+func altTextRows(shots []Move) string {
+	return altTextByRow(shots, func(positions []string) string {
+		return strings.Join(positions, ", ")
+	})
+}
+
+// altTextRowCounts counts the hits and misses row by row I.E. "Row A hits 2; misses 1."
+func altTextRowCounts(shots []Move) string {
+	return altTextByRow(shots, func(positions []string) string {
+		return fmt.Sprint(len(positions))
+	})
+}
+
+func altTextByRow(shots []Move, list func([]string) string) string {
+	if len(shots) == 0 {
+		return "no shots."
+	}
+
+	hits, misses := shotsByRow(shots)
+	rows := []string{}
+
+	for y := 0; y < 10; y++ {
+		parts := []string{}
+		if len(hits[y]) > 0 {
+			parts = append(parts, "hits "+list(hits[y]))
+		}
+
+		if len(misses[y]) > 0 {
+			parts = append(parts, "misses "+list(misses[y]))
+		}
+
+		if len(parts) > 0 {
+			rows = append(rows, fmt.Sprintf("Row %c %s", 'A'+y, strings.Join(parts, "; ")))
+		}
+	}
+
+	return strings.Join(rows, ". ") + "."
+}
+
+// altTextTotals only counts the hits and misses on the whole board.
+func altTextTotals(shots []Move) string {
+	hits := 0
+	for _, m := range shots {
+		if m.Hit {
+			hits++
+		}
+	}
+
+	return fmt.Sprintf("%d hits and %d misses.", hits, len(shots)-hits)
+}
+
+func truncateRunes(text string, max int) string {
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+
+	runes := []rune(text)
+
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,61 @@
+package twittership
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+)
+
+// lastMoveBy returns the most recent move made by side.
+func lastMoveBy(moves []Move, side Side) (Move, bool) {
+	for i := len(moves) - 1; i >= 0; i-- {
+		if moves[i].Side == side {
+			return moves[i], true
+		}
+	}
+
+	return Move{}, false
+}
+
+// moveCaption describes a move I.E. "Enemy fired at C4 - Hit!".
+func moveCaption(m Move) string {
+	result := "Miss"
+	if m.Hit {
+		result = "Hit!"
+	}
+
+	if m.Sunk != "" {
+		result = fmt.Sprintf("Hit! Sunk the %s", m.Sunk)
+	}
+
+	return fmt.Sprintf("%s fired at %s - %s", m.Side, m.Position, result)
+}
+
+// drawShotNumber draws the number of a move on a small badge in the top left corner of the
+// tile it was fired at, inside any highlight around the tile.
+func (gi GameImage) drawShotNumber(m Move) {
+	board := gi.enemyImage
+	if m.Side == EnemySide {
+		board = gi.playerImage
+	}
+
+	tile := board.tile(m.X, m.Y).Add(board.img.Rect.Min).Inset(board.theme.LineWidth)
+	text := strconv.Itoa(m.Number)
+	scale := largestTextScale(text, tile.Size().Div(2), board.scale)
+	size := textSize(text, scale)
+
+	badge := image.Rectangle{Min: tile.Min, Max: tile.Min.Add(size).Add(image.Pt(2*board.scale, 0))}
+	fillRect(board.img, badge, board.theme.Background)
+	drawText(board.img, text, centerOf(badge), scale, board.theme.Label)
+}
+
+// drawCaption draws text centred on the caption banner below the boards.
+func (gi GameImage) drawCaption(text string) {
+	l := gi.layout
+	theme := gi.playerImage.theme
+	caption := l.caption()
+
+	fillRect(gi.fullImage, caption, theme.Header)
+	fillRect(gi.fullImage, image.Rect(caption.Min.X, caption.Min.Y, caption.Max.X, caption.Min.Y+l.line), theme.Grid)
+	drawText(gi.fullImage, text, centerOf(caption), largestTextScale(text, caption.Inset(4*l.scale).Size(), 2*l.scale), theme.Label)
+}
@@ -0,0 +1,324 @@
+package twittership
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// roundName names a round of a tournament for the bracket, I.E. "Semifinals".
+func (t *Tournament) roundName(m tournamentMatch) string {
+	switch m.Bracket {
+	case LosersBracket:
+		return fmt.Sprintf("Losers Round %d", m.Round)
+	case GrandFinal:
+		if m.Round == 2 {
+			return "Grand Final Reset"
+		}
+
+		return "Grand Final"
+	}
+
+	prefix := ""
+	if t.opts.Format == DoubleElimination {
+		prefix = "Winners "
+	}
+
+	if t.opts.Format == SingleElimination || t.opts.Format == DoubleElimination {
+		switch t.winnersRounds() - m.Round {
+		case 0:
+			return prefix + "Final"
+		case 1:
+			return prefix + "Semifinals"
+		}
+	}
+
+	return fmt.Sprintf("%sRound %d", prefix, m.Round)
+}
+
+// winnersRounds returns the number of rounds in the winners bracket.
+func (t *Tournament) winnersRounds() int {
+	rounds := 0
+	for _, m := range t.matches {
+		if m.Bracket == WinnersBracket && m.Round > rounds {
+			rounds = m.Round
+		}
+	}
+
+	return rounds
+}
+
+// slotLabel names the player in a slot of a match, "TBD" until they are known.
+func (m tournamentMatch) slotLabel(slot int) string {
+	switch {
+	case !m.filled[slot]:
+		return "TBD"
+	case m.Players[slot] == "":
+		return "bye"
+	}
+
+	return fmt.Sprintf("(%d) %s", m.Seeds[slot], m.Players[slot])
+}
+
+// standingsRows formats the standings as rows of a table with a header. The tiebreaks are
+// only included for Swiss and round robin tournaments.
+func (t *Tournament) standingsRows() ([][]string, []bool) {
+	elimination := t.opts.Format == SingleElimination || t.opts.Format == DoubleElimination
+
+	rows := [][]string{{"#", "Player", "Seed", "W-L", "Games"}}
+	right := []bool{true, false, true, true, true}
+	if !elimination {
+		rows[0] = append(rows[0], "Pts", "Bch", "SB")
+		right = append(right, true, true, true)
+	}
+
+	for _, s := range t.standings() {
+		row := []string{
+			fmt.Sprintf("%d", s.Rank),
+			s.Handle,
+			fmt.Sprintf("%d", s.Seed),
+			fmt.Sprintf("%d-%d", s.MatchWins, s.MatchLosses),
+			fmt.Sprintf("%d-%d", s.GameWins, s.GameLosses),
+		}
+
+		if !elimination {
+			row = append(row, fmt.Sprintf("%g", s.Points), fmt.Sprintf("%g", s.Buchholz), fmt.Sprintf("%g", s.SonnebornBerger))
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, right
+}
+
+// GetTournamentText returns every match of the tournament round by round followed by the
+// standings. The winner of each match is marked with a *.
+func GetTournamentText(t *Tournament) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s, best of %d)\n", t.opts.Name, t.opts.Format, t.opts.BestOf)
+
+	round := ""
+	for _, m := range t.matches {
+		if name := t.roundName(m); name != round {
+			round = name
+			fmt.Fprintf(&b, "\n%s\n", round)
+		}
+
+		var players [2]string
+		for slot := range players {
+			players[slot] = m.slotLabel(slot)
+			if m.done && m.Winner != "" && m.Winner == m.Players[slot] {
+				players[slot] += "*"
+			}
+		}
+
+		fmt.Fprintf(&b, "  %s  %s %d-%d %s\n", m.ID, players[0], m.Wins[0], m.Wins[1], players[1])
+	}
+
+	rows, right := t.standingsRows()
+	b.WriteString("\nStandings\n")
+	b.WriteString(textTable(rows, right))
+
+	return b.String()
+}
+
+// TournamentImageOptions configures how NewTournamentImage draws a tournament.
+type TournamentImageOptions struct {
+	// Theme selects the colors. The zero value uses ClassicTheme.
+	Theme Theme
+	// Title is drawn above the bracket. It defaults to the name of the tournament.
+	Title string
+}
+
+const (
+	// bracketRowHeight is the height of each player in a match box.
+	bracketRowHeight = 22
+	// bracketSpacing is the height taken by each match in the first round.
+	bracketSpacing  = 56
+	bracketBoxWidth = 170
+	// bracketColumnWidth is the width of a round including the connectors to the next round.
+	bracketColumnWidth = 210
+	// bracketRoundHeight is the height of the band naming the rounds.
+	bracketRoundHeight = 30
+)
+
+// TournamentImage is a tournament drawn as a bracket, or as a standings table for Swiss and
+// round robin tournaments.
+type TournamentImage struct {
+	fullImage *image.RGBA
+}
+
+// NewTournamentImage draws an elimination tournament as a bracket with the losers bracket
+// below the winners bracket, and a Swiss or round robin tournament as its standings.
+func NewTournamentImage(t *Tournament, opts TournamentImageOptions) (TournamentImage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if opts.Theme.Name == "" {
+		opts.Theme = ClassicTheme
+	}
+
+	if opts.Title == "" {
+		opts.Title = t.opts.Name
+	}
+
+	if t.opts.Format == Swiss || t.opts.Format == RoundRobin {
+		rows, _ := t.standingsRows()
+		width := 600
+		img := newTitledImage(width, tableTitleHeight+len(rows)*tableRowHeight, opts.Title, opts.Theme)
+		drawTable(img, image.Rect(0, tableTitleHeight, width, img.Rect.Max.Y), rows, []float64{0.08, 0.32, 0.1, 0.1, 0.12, 0.08, 0.1, 0.1}, opts.Theme, func(int, int, string) color.RGBA {
+			return opts.Theme.Label
+		})
+
+		return TournamentImage{fullImage: img}, nil
+	}
+
+	return TournamentImage{fullImage: t.drawBracket(opts)}, nil
+}
+
+// drawBracket draws every match of an elimination tournament in a column per round, with
+// lines joining each match to the match its winner plays next.
+func (t *Tournament) drawBracket(opts TournamentImageOptions) *image.RGBA {
+	winnersRounds := t.winnersRounds()
+	firstRound := 1 << (winnersRounds - 1)
+
+	// The grand final follows the last round of either bracket
+	finalColumn := winnersRounds
+	for _, m := range t.matches {
+		if m.Bracket == LosersBracket && m.Round > finalColumn {
+			finalColumn = m.Round
+		}
+	}
+
+	// Each match is centred between the two matches that feed it in the round before
+	boxes := make([]image.Rectangle, len(t.matches))
+	top := tableTitleHeight + bracketRoundHeight
+	losersTop := top + firstRound*bracketSpacing + bracketRoundHeight
+	finalY := 0
+	width, height := 0, top+firstRound*bracketSpacing
+
+	index := map[TournamentBracket]map[int]int{WinnersBracket: {}, LosersBracket: {}, GrandFinal: {}}
+	for i, m := range t.matches {
+		n := index[m.Bracket][m.Round]
+		index[m.Bracket][m.Round]++
+
+		var column int
+		var y float64
+		switch m.Bracket {
+		case WinnersBracket:
+			column = m.Round - 1
+			y = float64(top) + (float64(n)+0.5)*float64(int(1)<<(m.Round-1))*bracketSpacing
+		case LosersBracket:
+			column = m.Round - 1
+			y = float64(losersTop) + (float64(n)+0.5)*float64(int(1)<<((m.Round-1)/2))*bracketSpacing
+		case GrandFinal:
+			column = finalColumn + m.Round - 1
+			y = float64(finalY)
+		}
+
+		box := image.Rect(0, 0, bracketBoxWidth, 2*bracketRowHeight)
+		box = box.Add(image.Pt(bracketSpacing/2+column*bracketColumnWidth, int(y)-bracketRowHeight))
+		boxes[i] = box
+
+		if m.Bracket == WinnersBracket && m.Round == winnersRounds {
+			finalY = int(y)
+		}
+
+		if box.Max.X+bracketSpacing/2 > width {
+			width = box.Max.X + bracketSpacing/2
+		}
+
+		if box.Max.Y+bracketSpacing/2 > height {
+			height = box.Max.Y + bracketSpacing/2
+		}
+	}
+
+	img := newTitledImage(width, height, opts.Title, opts.Theme)
+
+	labelled := map[string]bool{}
+	for i, m := range t.matches {
+		box := boxes[i]
+
+		// Each round is named above its first match, the grand final above its match
+		name := t.roundName(m)
+		if !labelled[name] {
+			labelled[name] = true
+
+			y := top - bracketRoundHeight/2
+			switch {
+			case m.Bracket == LosersBracket:
+				y = losersTop - bracketRoundHeight/2
+			case m.Bracket == GrandFinal:
+				y = box.Min.Y - bracketRoundHeight/2
+			}
+
+			drawText(img, name, image.Pt(box.Min.X+box.Dx()/2, y), 1, opts.Theme.Label)
+		}
+
+		for slot := 0; slot < 2; slot++ {
+			row := image.Rect(box.Min.X, box.Min.Y+slot*bracketRowHeight, box.Max.X, box.Min.Y+(slot+1)*bracketRowHeight)
+			if m.done && m.Winner != "" && m.Winner == m.Players[slot] {
+				fillRect(img, row, opts.Theme.Header)
+			}
+
+			label := m.slotLabel(slot)
+			size := textSize(label, 1)
+			drawText(img, label, image.Pt(row.Min.X+6+size.X/2, row.Min.Y+row.Dy()/2), 1, opts.Theme.Label)
+
+			if len(m.GameIDs) > 0 {
+				wins := fmt.Sprintf("%d", m.Wins[slot])
+				drawText(img, wins, image.Pt(row.Max.X-10, row.Min.Y+row.Dy()/2), 1, opts.Theme.Label)
+			}
+		}
+
+		drawOutline(img, box, opts.Theme.Grid)
+		fillRect(img, image.Rect(box.Min.X, box.Min.Y+bracketRowHeight, box.Max.X, box.Min.Y+bracketRowHeight+1), opts.Theme.Grid)
+
+		if m.winnerTo != nil {
+			to := boxes[m.winnerTo.match]
+			if to.Empty() {
+				continue
+			}
+
+			fromY := box.Min.Y + box.Dy()/2
+			toY := to.Min.Y + m.winnerTo.slot*bracketRowHeight + bracketRowHeight/2
+			midX := box.Max.X + (to.Min.X-box.Max.X)/2
+
+			fillRect(img, image.Rect(box.Max.X, fromY, midX, fromY+1), opts.Theme.Grid)
+			vertical := image.Rect(midX, fromY, midX+1, toY).Canon()
+			vertical.Max.Y++
+			fillRect(img, vertical, opts.Theme.Grid)
+			fillRect(img, image.Rect(midX, toY, to.Min.X, toY+1), opts.Theme.Grid)
+		}
+	}
+
+	return img
+}
+
+// drawOutline draws a one pixel border just inside r.
+func drawOutline(img *image.RGBA, r image.Rectangle, c color.Color) {
+	fillRect(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+1), c)
+	fillRect(img, image.Rect(r.Min.X, r.Max.Y-1, r.Max.X, r.Max.Y), c)
+	fillRect(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+1, r.Max.Y), c)
+	fillRect(img, image.Rect(r.Max.X-1, r.Min.Y, r.Max.X, r.Max.Y), c)
+}
+
+// Encode writes the tournament image to w in the format provided.
+func (ti TournamentImage) Encode(w io.Writer, format ImageFormat) error {
+	err := encodeImage(w, ti.fullImage, format)
+	if err != nil {
+		return fmt.Errorf("unable to encode tournament image: %w", err)
+	}
+
+	return nil
+}
+
+// GetFullImage returns the image.RGBA fullImage.
+func (ti TournamentImage) GetFullImage() *image.RGBA {
+	return ti.fullImage
+}
@@ -0,0 +1,207 @@
+package twittership
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// renderCacheSize is the most entries a render cache holds. A full cache is emptied rather
+// than tracking which entry is the oldest, it only fills up when many sizes are drawn.
+const renderCacheSize = 64
+
+// renderCache keeps images that are expensive to draw but never change once drawn, so
+// they can be shared by every game image.
+type renderCache struct {
+	mu      sync.Mutex
+	entries map[interface{}]interface{}
+}
+
+// get returns the entry for key, calling create to make it if it isn't cached yet.
+func (c *renderCache) get(key interface{}, create func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok {
+		return entry, nil
+	}
+
+	entry, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil || len(c.entries) >= renderCacheSize {
+		c.entries = map[interface{}]interface{}{}
+	}
+
+	c.entries[key] = entry
+
+	return entry, nil
+}
+
+// find returns the key of the first cached entry that match accepts.
+func (c *renderCache) find(match func(entry interface{}) bool) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if match(entry) {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+var (
+	baseLayers    renderCache
+	glyphMasks    renderCache
+	templateFiles renderCache
+)
+
+// baseLayerKey is everything the frame and empty boards of a game image depend on.
+type baseLayerKey struct {
+	layout layout
+	theme  Theme
+	// template identifies the template, see templateID
+	template interface{}
+}
+
+// embeddedTemplateID identifies the embedded template in a baseLayerKey.
+type embeddedTemplateID struct{}
+
+// templateID returns a key identifying a template the package keeps for reuse: no template,
+// the embedded template or a template file. Any other template is decoded by the caller for
+// each image, so a layer keyed by it would never be used again and isn't worth caching.
+func templateID(template image.Image) (interface{}, bool) {
+	if template == nil {
+		return nil, true
+	}
+
+	// Comparing templates of the same type that can't be compared panics
+	if !reflect.TypeOf(template).Comparable() {
+		return nil, false
+	}
+
+	if embedded, err := DefaultTemplate(); err == nil && embedded == template {
+		return embeddedTemplateID{}, true
+	}
+
+	return templateFiles.find(func(entry interface{}) bool {
+		return entry == template
+	})
+}
+
+// baseLayer returns the frame and the empty boards for a layout. Layers are only cached
+// for the templates the package keeps, see templateID.
+func baseLayer(l layout, theme Theme, template image.Image) *image.RGBA {
+	id, ok := templateID(template)
+	if !ok {
+		return drawBaseLayer(l, theme, template)
+	}
+
+	base, _ := baseLayers.get(baseLayerKey{l, theme, id}, func() (interface{}, error) {
+		return drawBaseLayer(l, theme, template), nil
+	})
+
+	return base.(*image.RGBA)
+}
+
+func drawBaseLayer(l layout, theme Theme, template image.Image) *image.RGBA {
+	img := image.NewRGBA(l.canvas)
+
+	// The template is only used when it is drawn for exactly this layout, otherwise the
+	// frame is drawn to fit
+	frame := image.Rectangle{Min: l.origin, Max: l.origin.Add(l.frameSize())}
+	frame.Max.Y -= l.panelsHeight()
+
+	if template != nil && template.Bounds().Size() == frame.Size() {
+		fillRect(img, img.Bounds(), theme.Background)
+		draw.Draw(img, frame, template, template.Bounds().Min, draw.Over)
+	} else {
+		drawFrame(img, l, theme)
+	}
+
+	for _, side := range []Side{PlayerSide, EnemySide} {
+		ui := userImage{layout: l, theme: theme, img: img.SubImage(l.board(side)).(*image.RGBA)}
+		ui.drawBackground()
+	}
+
+	return img
+}
+
+// glyphMaskKey is everything the shape of a glyph depends on.
+type glyphMaskKey struct {
+	style        GlyphStyle
+	lineWidth    int
+	glyphPadding int
+	size         image.Point
+}
+
+// glyphMask returns a mask of the pixels on a glyph for a whole tile, including the grid
+// lines at the top and left.
+func (t Theme) glyphMask(style GlyphStyle, size image.Point) *image.Alpha {
+	key := glyphMaskKey{style, t.LineWidth, t.GlyphPadding, size}
+
+	mask, _ := glyphMasks.get(key, func() (interface{}, error) {
+		mask := image.NewAlpha(image.Rect(0, 0, size.X, size.Y))
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				if t.isPointOnGlyph(style, x, y, size.X, size.Y) {
+					mask.Pix[mask.PixOffset(x, y)] = 0xff
+				}
+			}
+		}
+
+		return mask, nil
+	})
+
+	return mask.(*image.Alpha)
+}
+
+// templateFileKey identifies a template file, it changes whenever the file is changed.
+type templateFileKey struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// loadTemplateFile decodes the template at path, reusing the decoded image until the file
+// changes.
+func loadTemplateFile(path string) (image.Image, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening game_template: %w", err)
+	}
+
+	key := templateFileKey{path, info.ModTime(), info.Size()}
+
+	tmpl, err := templateFiles.get(key, func() (interface{}, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("opening game_template: %w", err)
+		}
+		defer f.Close()
+
+		tmpl, _, err := image.Decode(f)
+		if err != nil {
+			return nil, fmt.Errorf("decoding game_template: %w", err)
+		}
+
+		return tmpl, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tmpl.(image.Image), nil
+}
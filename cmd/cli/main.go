@@ -1,8 +1,8 @@
 package main
 
 import (
-	"fmt"
 	"log"
+	"os"
 	"twittership"
 )
 
@@ -28,5 +28,9 @@ func main() {
 		log.Fatalf("Unable to load enemy volleys: %s", err)
 	}
 
-	fmt.Println(twittership.GetGameTextFromGame(g))
+	renderer := twittership.NewTextRenderer(twittership.TextOptions{Legend: true, FleetStatus: true})
+	err = renderer.Write(os.Stdout, g)
+	if err != nil {
+		log.Fatalf("Unable to write game: %s", err)
+	}
 }
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"time"
+	"twittership"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	template := flag.String("template", "", "path to the board template image, the embedded template is used when empty")
+	turnDeadline := flag.Duration("turn-deadline", 24*time.Hour, "time a side has to move before forfeiting, 0 disables timeouts")
+	statsFile := flag.String("stats", "stats.json", "path to the file player statistics are kept in")
+	flag.Parse()
+
+	store, err := twittership.NewFileStatsStore(*statsFile)
+	if err != nil {
+		log.Fatalf("Unable to load player statistics: %v", err)
+	}
+
+	m := twittership.NewManager()
+	m.SetStats(twittership.NewStats(store))
+	m.SetStatsErrorHandler(func(gameID string, err error) {
+		log.Printf("Game %s: unable to record player statistics, it will be retried: %v", gameID, err)
+	})
+	m.SetTimeoutPolicy(twittership.TimeoutPolicy{
+		TurnDeadline: *turnDeadline,
+		Reminders:    []time.Duration{*turnDeadline / 2, *turnDeadline / 24},
+	}, func(notice twittership.TimeoutNotice) {
+		if notice.Forfeit {
+			log.Printf("Game %s: %s forfeited by not moving before %s", notice.GameID, notice.Side, notice.Deadline)
+			return
+		}
+
+		log.Printf("Game %s: %s has %s left to move", notice.GameID, notice.Side, notice.Remaining)
+	})
+
+	go m.RunTimeouts(context.Background(), time.Minute)
+
+	log.Printf("Listening on %s", *addr)
+	err = http.ListenAndServe(*addr, twittership.NewServer(m, *template))
+	if err != nil {
+		log.Fatalf("Unable to serve: %v", err)
+	}
+}
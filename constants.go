@@ -1,5 +1,10 @@
 package twittership
 
+import (
+	"fmt"
+	"strings"
+)
+
 type shipDirection int
 
 const (
@@ -24,6 +29,9 @@ const (
 	shipDestroyer
 )
 
+// fleetSize is the number of ships each side places.
+const fleetSize = 5
+
 func (s shipType) String() string {
 	return [...]string{"Aircraft Carrier", "Battleship", "Submarine", "Cruiser", "Destroyer"}[s]
 }
@@ -59,3 +67,75 @@ func getShipWidth(shipType shipType) int {
 
 	return 0
 }
+
+// Side identifies one of the two sides playing a game.
+type Side int
+
+const (
+	// PlayerSide is the side whose ships are on the player board.
+	PlayerSide Side = iota
+	// EnemySide is the side whose ships are on the enemy board.
+	EnemySide
+)
+
+func (s Side) String() string {
+	return [...]string{"Player", "Enemy"}[s]
+}
+
+// MarshalText encodes the side as "player" or "enemy".
+func (s Side) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(s.String())), nil
+}
+
+// UnmarshalText decodes a side from "player" or "enemy".
+func (s *Side) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "player":
+		*s = PlayerSide
+	case "enemy":
+		*s = EnemySide
+	default:
+		return fmt.Errorf("unknown side: %s", text)
+	}
+
+	return nil
+}
+
+// Opponent returns the other side of the game.
+func (s Side) Opponent() Side {
+	if s == PlayerSide {
+		return EnemySide
+	}
+
+	return PlayerSide
+}
+
+// FinishReason describes why a game has finished.
+type FinishReason int
+
+const (
+	// NotFinished means the game is still being played.
+	NotFinished FinishReason = iota
+	// AllShipsSunk means one side sunk every ship of the other side.
+	AllShipsSunk
+	// Forfeited means one side gave up.
+	Forfeited
+	// TimedOut means one side did not move before the turn deadline.
+	TimedOut
+)
+
+func (r FinishReason) String() string {
+	return [...]string{"Not Finished", "All Ships Sunk", "Forfeited", "Timed Out"}[r]
+}
+
+// Boards selects which boards a text renderer draws.
+type Boards int
+
+const (
+	// BothBoards draws the player board and the enemy board side by side.
+	BothBoards Boards = iota
+	// PlayerBoardOnly only draws the player board.
+	PlayerBoardOnly
+	// EnemyBoardOnly only draws the enemy board.
+	EnemyBoardOnly
+)
@@ -0,0 +1,161 @@
+package twittership
+
+import (
+	"strings"
+)
+
+// MaxTweetLength is the most characters Twitter allows in a tweet.
+const MaxTweetLength = 280
+
+const (
+	emojiWater = "🌊"
+	emojiShip  = "🚢"
+	emojiHit   = "💥"
+	emojiMiss  = "⚪"
+	emojiSunk  = "☠️"
+	emojiBlank = "⬛"
+)
+
+// emojiColumns are the keycap emoji used to label the columns.
+var emojiColumns = [...]string{"1️⃣", "2️⃣", "3️⃣", "4️⃣", "5️⃣", "6️⃣", "7️⃣", "8️⃣", "9️⃣", "🔟"}
+
+// EmojiOptions configures how GetGameEmojiFromGame draws a game.
+type EmojiOptions struct {
+	Boards Boards
+	// Labels adds the column numbers above and the row letters beside each board.
+	Labels bool
+}
+
+// GetGameEmojiFromGame will return the game as an emoji grid which displays properly on
+// Twitter, unlike GetGameTextFromGame. Water is 🌊, ships 🚢, hits 💥, misses ⚪ and sunk
+// ships ☠️. Ships on the enemy board are only shown once they have been sunk.
+func GetGameEmojiFromGame(g Game, opts EmojiOptions) string {
+	type emojiBoard struct {
+		tiles [10][10]boardTile
+		ships []ship
+		own   bool
+	}
+
+	var boards []emojiBoard
+	if opts.Boards != EnemyBoardOnly {
+		boards = append(boards, emojiBoard{g.playerBoard, g.playerShips, true})
+	}
+
+	if opts.Boards != PlayerBoardOnly {
+		boards = append(boards, emojiBoard{g.enemyBoard, g.enemyShips, false})
+	}
+
+	var sb strings.Builder
+
+	if opts.Labels {
+		for i := range boards {
+			if i > 0 {
+				sb.WriteString(" ")
+			}
+
+			sb.WriteString(emojiBlank)
+			for _, column := range emojiColumns {
+				sb.WriteString(column)
+			}
+		}
+
+		sb.WriteString("\n")
+	}
+
+	for y := 0; y < 10; y++ {
+		for i, board := range boards {
+			if i > 0 {
+				sb.WriteString(" ")
+			}
+
+			if opts.Labels {
+				sb.WriteString(emojiRowLabel(y))
+			}
+
+			for x := 0; x < 10; x++ {
+				sb.WriteString(getTileEmoji(board.tiles[y][x], board.ships, board.own))
+			}
+		}
+
+		if y < 9 {
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// GetTweetEmojiFromGame will return the opponent board as seen by side as an emoji grid that
+// always fits in a single tweet. The row and column labels are included when they fit.
+func GetTweetEmojiFromGame(g Game, side Side) string {
+	opts := EmojiOptions{Boards: EnemyBoardOnly, Labels: true}
+
+	text := GetGameEmojiFromGame(g.perspective(side), opts)
+	if TweetLength(text) <= MaxTweetLength {
+		return text
+	}
+
+	opts.Labels = false
+
+	return GetGameEmojiFromGame(g.perspective(side), opts)
+}
+
+func getTileEmoji(tile boardTile, ships []ship, own bool) string {
+	if tile.shipIndex != -1 && ships[tile.shipIndex].hits >= ships[tile.shipIndex].width {
+		return emojiSunk
+	}
+
+	if tile.volleyIndex != -1 && tile.shipIndex != -1 {
+		return emojiHit
+	}
+
+	if tile.volleyIndex != -1 {
+		return emojiMiss
+	}
+
+	if tile.shipIndex != -1 && own {
+		return emojiShip
+	}
+
+	return emojiWater
+}
+
+// emojiRowLabel returns the regional indicator letter for a row.
+func emojiRowLabel(y int) string {
+	return string(rune(0x1F1E6 + y))
+}
+
+// TweetLength returns the length of text as counted by Twitter. Most characters outside of
+// the Latin, Greek and Cyrillic scripts count as two, and an emoji counts as two no matter
+// how many code points it is made of.
+func TweetLength(text string) int {
+	length := 0
+	joined := false
+	flag := false
+
+	for _, r := range text {
+		isRegional := r >= 0x1F1E6 && r <= 0x1F1FF
+
+		switch {
+		case r == '\uFE0F':
+			// Variation selectors are part of the previous emoji
+		case r == '\u20E3':
+			// Keycaps turn the previous digit, which counted as one, into an emoji
+			length++
+		case r == '\u200D':
+			joined = true
+			continue
+		case joined, isRegional && flag:
+			// The rest of a joined emoji or the second regional indicator of a flag
+		case r <= 4351, r >= 8192 && r <= 8205, r >= 8208 && r <= 8223, r >= 8242 && r <= 8247:
+			length++
+		default:
+			length += 2
+		}
+
+		flag = isRegional && !flag
+		joined = false
+	}
+
+	return length
+}
@@ -0,0 +1,149 @@
+package twittership
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ErrUnknownImageFormat is returned when encoding an image with an unknown encoding.
+var ErrUnknownImageFormat = errors.New("unknown image format")
+
+// Encoding is the file format an image is encoded as.
+type Encoding int
+
+const (
+	// EncodingPNG is a lossless PNG, the default.
+	EncodingPNG Encoding = iota
+	// EncodingJPEG is a lossy JPEG which is usually smaller than a PNG.
+	EncodingJPEG
+	// EncodingGIF is a GIF with up to 256 of the most common colors in the image.
+	EncodingGIF
+)
+
+// ImageFormat configures how an image is encoded. The zero value is a PNG with the default
+// compression.
+type ImageFormat struct {
+	Encoding Encoding
+	// PNGCompression is the compression level of a PNG.
+	PNGCompression png.CompressionLevel
+	// JPEGQuality is the quality of a JPEG from 1 to 100. It defaults to jpeg.DefaultQuality.
+	JPEGQuality int
+}
+
+// ImageFormatByName returns the format with the default settings for a name or file
+// extension I.E. "png", "jpeg", "jpg" or "gif".
+func ImageFormatByName(name string) (ImageFormat, bool) {
+	switch name {
+	case "png", ".png":
+		return ImageFormat{Encoding: EncodingPNG}, true
+	case "jpeg", "jpg", ".jpeg", ".jpg":
+		return ImageFormat{Encoding: EncodingJPEG}, true
+	case "gif", ".gif":
+		return ImageFormat{Encoding: EncodingGIF}, true
+	}
+
+	return ImageFormat{}, false
+}
+
+// ContentType returns the MIME type of the format which is needed when uploading media.
+func (f ImageFormat) ContentType() string {
+	switch f.Encoding {
+	case EncodingJPEG:
+		return "image/jpeg"
+	case EncodingGIF:
+		return "image/gif"
+	}
+
+	return "image/png"
+}
+
+// Encode writes the game image to w in the format provided.
+func (gi GameImage) Encode(w io.Writer, format ImageFormat) error {
+	err := encodeImage(w, gi.fullImage, format)
+	if err != nil {
+		return fmt.Errorf("unable to encode game board image: %w", err)
+	}
+
+	return nil
+}
+
+// WriteImage will write a PNG to the disk at the location provided by filename.
+func (gi GameImage) WriteImage(filename string) error {
+	return gi.WriteImageAs(filename, ImageFormat{})
+}
+
+// WriteImageAs will write the image to the disk at the location provided by filename in the
+// format provided. The image is written to a temporary file first and then renamed so the
+// file is never left half written.
+func (gi GameImage) WriteImageAs(filename string, format ImageFormat) error {
+	err := writeFileAtomic(filename, func(w io.Writer) error {
+		return gi.Encode(w, format)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to write game board image: %w", err)
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes to a temporary file in the same directory and renames it over
+// filename once it is complete, so readers never see a partly written file.
+func writeFileAtomic(filename string, write func(w io.Writer) error) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	// Removing the temporary file fails once it has been renamed, which is expected
+	defer os.Remove(f.Name())
+
+	err = write(f)
+	if err == nil {
+		err = f.Sync()
+	}
+
+	closeErr := f.Close()
+	if err != nil {
+		return err
+	}
+
+	if closeErr != nil {
+		return closeErr
+	}
+
+	return os.Rename(f.Name(), filename)
+}
+
+func encodeImage(w io.Writer, img *image.RGBA, format ImageFormat) error {
+	switch format.Encoding {
+	case EncodingPNG:
+		e := png.Encoder{CompressionLevel: format.PNGCompression}
+		return e.Encode(w, img)
+	case EncodingJPEG:
+		quality := format.JPEGQuality
+		if quality == 0 {
+			quality = jpeg.DefaultQuality
+		}
+
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	case EncodingGIF:
+		// The default GIF palette dithers the flat theme colors so the most common colors
+		// are used instead
+		pal := framePalette(img)
+		paletted := image.NewPaletted(img.Bounds(), pal)
+		draw.Draw(paletted, paletted.Rect, img, img.Rect.Min, draw.Src)
+
+		return gif.Encode(w, paletted, &gif.Options{NumColors: len(pal)})
+	}
+
+	return fmt.Errorf("%w: %d", ErrUnknownImageFormat, format.Encoding)
+}
@@ -0,0 +1,153 @@
+package twittership
+
+// Event is implemented by every event a Game delivers to its subscribers. Use a type
+// switch to tell the events apart.
+type Event interface {
+	isEvent()
+}
+
+// ShipPlacedEvent is delivered for every ship once a side has placed their ships.
+type ShipPlacedEvent struct {
+	Side Side
+	Ship ShipView
+}
+
+// ShotFiredEvent is delivered whenever a side fires a volley, before the HitEvent or
+// MissEvent describing the result.
+type ShotFiredEvent struct {
+	Move Move
+}
+
+// HitEvent is delivered when a volley hits a ship.
+type HitEvent struct {
+	Move Move
+}
+
+// MissEvent is delivered when a volley misses every ship.
+type MissEvent struct {
+	Move Move
+}
+
+// SunkEvent is delivered when a volley sinks a ship. Owner is the side the sunk ship
+// belonged to.
+type SunkEvent struct {
+	Move  Move
+	Owner Side
+	Ship  string
+}
+
+// GameOverEvent is delivered once when the game finishes.
+type GameOverEvent struct {
+	Winner Side
+	Reason FinishReason
+}
+
+// TurnChangedEvent is delivered whenever the side expected to act next changes.
+type TurnChangedEvent struct {
+	Turn Side
+}
+
+func (ShipPlacedEvent) isEvent()  {}
+func (ShotFiredEvent) isEvent()   {}
+func (HitEvent) isEvent()         {}
+func (MissEvent) isEvent()        {}
+func (SunkEvent) isEvent()        {}
+func (GameOverEvent) isEvent()    {}
+func (TurnChangedEvent) isEvent() {}
+
+type subscriber struct {
+	id      int
+	handler func(Event)
+}
+
+// Subscribe registers handler to receive every event the game produces from now on.
+// Events are delivered synchronously, in order, from within the call that caused them
+// so the handler must not call back into the game. The returned function removes the
+// subscription.
+func (g *Game) Subscribe(handler func(Event)) func() {
+	g.nextSubscriberID++
+	id := g.nextSubscriberID
+	g.subscribers = append(g.subscribers, subscriber{id: id, handler: handler})
+
+	return func() {
+		for i, s := range g.subscribers {
+			if s.id == id {
+				g.subscribers = append(g.subscribers[:i:i], g.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// eventState is the part of a game that is compared before and after an action to
+// work out which events the action caused.
+type eventState struct {
+	turn     Side
+	moves    int
+	placed   [2]bool
+	finished bool
+}
+
+func (g Game) eventState() eventState {
+	return eventState{
+		turn:     g.Turn(),
+		moves:    len(g.moveOrder),
+		placed:   [2]bool{g.ShipsPlaced(PlayerSide), g.ShipsPlaced(EnemySide)},
+		finished: g.IsFinished(),
+	}
+}
+
+// emitSince delivers the events for everything that changed since before was taken.
+func (g *Game) emitSince(before eventState) {
+	if len(g.subscribers) == 0 {
+		return
+	}
+
+	var events []Event
+
+	for _, side := range []Side{PlayerSide, EnemySide} {
+		if before.placed[side] || !g.ShipsPlaced(side) {
+			continue
+		}
+
+		ships := g.playerShips
+		if side == EnemySide {
+			ships = g.enemyShips
+		}
+
+		for _, view := range shipViews(ships, false) {
+			events = append(events, ShipPlacedEvent{Side: side, Ship: view})
+		}
+	}
+
+	for _, m := range g.Moves()[before.moves:] {
+		events = append(events, ShotFiredEvent{Move: m})
+
+		if !m.Hit {
+			events = append(events, MissEvent{Move: m})
+			continue
+		}
+
+		events = append(events, HitEvent{Move: m})
+
+		if m.Sunk != "" {
+			events = append(events, SunkEvent{Move: m, Owner: m.Side.Opponent(), Ship: m.Sunk})
+		}
+	}
+
+	if winner, ok := g.Winner(); ok && !before.finished {
+		events = append(events, GameOverEvent{Winner: winner, Reason: g.FinishReason()})
+	}
+
+	if turn := g.Turn(); turn != before.turn && !g.IsFinished() {
+		events = append(events, TurnChangedEvent{Turn: turn})
+	}
+
+	// Copy the subscribers so a handler unsubscribing itself doesn't skip the next handler
+	subscribers := append([]subscriber(nil), g.subscribers...)
+	for _, event := range events {
+		for _, s := range subscribers {
+			s.handler(event)
+		}
+	}
+}
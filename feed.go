@@ -0,0 +1,226 @@
+package twittership
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"sort"
+	"time"
+)
+
+// ErrNoLiveGames is returned when drawing a digest of live games while no game is being played.
+var ErrNoLiveGames = errors.New("no games are being played")
+
+// FeedEntry is a move made in one of the games of a Manager.
+type FeedEntry struct {
+	GameID string    `json:"gameId"`
+	Move   Move      `json:"move"`
+	Time   time.Time `json:"time"`
+}
+
+// LiveGame is a game that is being played and its most recent move.
+type LiveGame struct {
+	ID string
+	// Game is a snapshot of the game. It still contains the hidden ships so it must only be
+	// shown to spectators through SpectatorView or an image with ImageOptions.Spectator.
+	Game     Game
+	LastMove FeedEntry
+}
+
+// liveGames returns a snapshot of every game that hasn't finished and has at least one move,
+// with the time of every move.
+func (m *Manager) liveGames() []LiveGame {
+	m.mu.RLock()
+	games := make(map[string]*managedGame, len(m.games))
+	for id, mg := range m.games {
+		games[id] = mg
+	}
+	m.mu.RUnlock()
+
+	live := make([]LiveGame, 0, len(games))
+	for id, mg := range games {
+		mg.mu.Lock()
+		if !mg.game.IsFinished() && len(mg.moveTimes) > 0 {
+			snapshot := mg.game.clone()
+			snapshot.subscribers = nil
+
+			moves := snapshot.Moves()
+			live = append(live, LiveGame{
+				ID:       id,
+				Game:     snapshot,
+				LastMove: FeedEntry{GameID: id, Move: moves[len(moves)-1], Time: mg.moveTimes[len(moves)-1]},
+			})
+		}
+		mg.mu.Unlock()
+	}
+
+	return live
+}
+
+// Feed returns the most recent moves across every game that is being played, newest first.
+// At most limit moves are returned, a limit of zero returns every move.
+func (m *Manager) Feed(limit int) []FeedEntry {
+	m.mu.RLock()
+	games := make(map[string]*managedGame, len(m.games))
+	for id, mg := range m.games {
+		games[id] = mg
+	}
+	m.mu.RUnlock()
+
+	feed := []FeedEntry{}
+	for id, mg := range games {
+		mg.mu.Lock()
+		if !mg.game.IsFinished() {
+			for i, move := range mg.game.Moves() {
+				feed = append(feed, FeedEntry{GameID: id, Move: move, Time: mg.moveTimes[i]})
+			}
+		}
+		mg.mu.Unlock()
+	}
+
+	sortFeed(feed)
+
+	if limit > 0 && len(feed) > limit {
+		feed = feed[:limit]
+	}
+
+	return feed
+}
+
+// LiveGames returns the games that are being played, the game with the most recent move
+// first. At most limit games are returned, a limit of zero returns every game.
+func (m *Manager) LiveGames(limit int) []LiveGame {
+	live := m.liveGames()
+
+	sort.Slice(live, func(i, j int) bool {
+		return feedEntryBefore(live[i].LastMove, live[j].LastMove)
+	})
+
+	if limit > 0 && len(live) > limit {
+		live = live[:limit]
+	}
+
+	return live
+}
+
+// sortFeed orders the feed newest first. Moves made at the same time are ordered by game
+// and then newest move first so the feed is always in the same order.
+func sortFeed(feed []FeedEntry) {
+	sort.Slice(feed, func(i, j int) bool {
+		return feedEntryBefore(feed[i], feed[j])
+	})
+}
+
+func feedEntryBefore(a, b FeedEntry) bool {
+	if !a.Time.Equal(b.Time) {
+		return a.Time.After(b.Time)
+	}
+
+	if a.GameID != b.GameID {
+		return a.GameID < b.GameID
+	}
+
+	return a.Move.Number > b.Move.Number
+}
+
+// DigestOptions configures how NewLiveDigestImage draws the live games.
+type DigestOptions struct {
+	// Theme selects the colors and glyphs. The zero value uses ClassicTheme.
+	Theme Theme
+	// Games is the most games that are drawn. It defaults to 4.
+	Games int
+	// Columns is the number of games drawn side by side. It defaults to 2.
+	Columns int
+	// BoardSize is the size of each board. It defaults to 201.
+	BoardSize int
+}
+
+// digestLabelHeight is the height of the band above each game with its id.
+const digestLabelHeight = 30
+
+// DigestImage is a single image showing several live games, for posting a "live games" digest.
+type DigestImage struct {
+	fullImage *image.RGBA
+}
+
+// NewLiveDigestImage draws the games with the most recent moves in a grid as spectators see
+// them, each with its id above it and the most recent move below it. It returns
+// ErrNoLiveGames when no games are being played.
+func NewLiveDigestImage(m *Manager, opts DigestOptions) (DigestImage, error) {
+	if opts.Games == 0 {
+		opts.Games = 4
+	}
+
+	if opts.Columns == 0 {
+		opts.Columns = 2
+	}
+
+	if opts.BoardSize == 0 {
+		opts.BoardSize = 201
+	}
+
+	if opts.Theme.Name == "" {
+		opts.Theme = ClassicTheme
+	}
+
+	games := m.LiveGames(opts.Games)
+	if len(games) == 0 {
+		return DigestImage{}, ErrNoLiveGames
+	}
+
+	cells := make([]*image.RGBA, 0, len(games))
+	for _, live := range games {
+		gi, err := NewGameImageWithOptions(live.Game, opts.BoardSize, opts.BoardSize, ImageOptions{
+			Theme:              opts.Theme,
+			Spectator:          true,
+			HighlightLastMoves: true,
+			Caption:            true,
+		})
+		if err != nil {
+			return DigestImage{}, fmt.Errorf("unable to draw live game %s: %w", live.ID, err)
+		}
+
+		cells = append(cells, gi.fullImage)
+	}
+
+	columns := opts.Columns
+	if len(cells) < columns {
+		columns = len(cells)
+	}
+
+	rows := (len(cells) + columns - 1) / columns
+	cell := cells[0].Bounds().Size().Add(image.Pt(0, digestLabelHeight))
+
+	img := image.NewRGBA(image.Rect(0, 0, columns*cell.X, rows*cell.Y))
+	fillRect(img, img.Rect, opts.Theme.Background)
+
+	for i, c := range cells {
+		origin := image.Pt(i%columns*cell.X, i/columns*cell.Y)
+
+		label := image.Rectangle{Min: origin, Max: origin.Add(image.Pt(cell.X, digestLabelHeight))}
+		fillRect(img, label, opts.Theme.Grid)
+		text := "Game " + games[i].ID
+		drawText(img, text, centerOf(label), largestTextScale(text, label.Inset(4).Size(), 2), opts.Theme.Background)
+
+		draw.Draw(img, c.Bounds().Add(image.Pt(origin.X, label.Max.Y)), c, image.Point{}, draw.Src)
+	}
+
+	return DigestImage{fullImage: img}, nil
+}
+
+// Encode writes the digest image to w in the format provided.
+func (di DigestImage) Encode(w io.Writer, format ImageFormat) error {
+	err := encodeImage(w, di.fullImage, format)
+	if err != nil {
+		return fmt.Errorf("unable to encode live digest image: %w", err)
+	}
+
+	return nil
+}
+
+// GetFullImage returns the image.RGBA fullImage.
+func (di DigestImage) GetFullImage() *image.RGBA {
+	return di.fullImage
+}
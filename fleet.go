@@ -0,0 +1,159 @@
+package twittership
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// ShipStatus describes how badly a ship has been damaged.
+type ShipStatus int
+
+const (
+	// Afloat ships have not been hit.
+	Afloat ShipStatus = iota
+	// Damaged ships have been hit but not sunk.
+	Damaged
+	// Sunk ships have been hit on every tile.
+	Sunk
+)
+
+func (s ShipStatus) String() string {
+	switch s {
+	case Afloat:
+		return "Afloat"
+	case Damaged:
+		return "Damaged"
+	case Sunk:
+		return "Sunk"
+	default:
+		return fmt.Sprintf("ShipStatus(%d)", int(s))
+	}
+}
+
+// FleetEntry is a single ship in a fleet status listing.
+type FleetEntry struct {
+	Name   string
+	Size   int
+	Status ShipStatus
+}
+
+// Status returns the status of the ship described by the view.
+func (v ShipView) Status() ShipStatus {
+	switch {
+	case v.Sunk:
+		return Sunk
+	case v.Hits > 0:
+		return Damaged
+	default:
+		return Afloat
+	}
+}
+
+// FleetStatus lists every ship of a side in the order they were placed. When forOpponent is
+// true the listing is what the opponent is allowed to know, damaged ships are reported as
+// afloat because the opponent can't tell which ship a hit belongs to until it sinks.
+func (g Game) FleetStatus(side Side, forOpponent bool) []FleetEntry {
+	entries := []FleetEntry{}
+
+	for _, view := range shipViews(g.perspective(side).playerShips, false) {
+		status := view.Status()
+		if forOpponent && status == Damaged {
+			status = Afloat
+		}
+
+		entries = append(entries, FleetEntry{Name: view.Name, Size: view.Size, Status: status})
+	}
+
+	return entries
+}
+
+// fleetLines returns a line per ship for the fleet status panel.
+func fleetLines(entries []FleetEntry) []string {
+	lines := []string{}
+	for _, e := range entries {
+		lines = append(lines, fmt.Sprintf("%s (%d): %s", e.Name, e.Size, e.Status))
+	}
+
+	return lines
+}
+
+// GetFleetStatusText will return the status of both fleets as text which can be printed below
+// the boards returned by GetGameTextFromGame. The enemy fleet only shows what the player is
+// allowed to know.
+func GetFleetStatusText(g Game) string {
+	player, enemy := g.FleetStatus(PlayerSide, false), g.FleetStatus(EnemySide, true)
+
+	output := "|---------------------------------------------|\n"
+	output += "|     PLAYER FLEET    | |     ENEMY FLEET     |\n"
+	output += "|---------------------------------------------|\n"
+
+	for i := 0; i < len(player) || i < len(enemy); i++ {
+		output += fmt.Sprintf("|%-21s| |%-21s|\n", fleetEntryText(player, i), fleetEntryText(enemy, i))
+	}
+
+	return output
+}
+
+// fleetEntryText returns a fleet entry short enough to fit in a text board column.
+func fleetEntryText(entries []FleetEntry, i int) string {
+	if i >= len(entries) {
+		return ""
+	}
+
+	name := strings.TrimPrefix(entries[i].Name, "Aircraft ")
+
+	return fmt.Sprintf(" %s %s", name, entries[i].Status)
+}
+
+// drawFleetPanel draws the fleet status of each side below their board. Damaged ships use
+// the hit color and sunk ships are struck through so the panel can be read at a glance.
+func (gi GameImage) drawFleetPanel(player, enemy []FleetEntry) {
+	l := gi.layout
+	theme := gi.playerImage.theme
+	panel := l.panel()
+	rowH := l.fleetRowHeight()
+	padding := panelPadding * l.scale / 2
+
+	fillRect(gi.fullImage, panel, theme.Background)
+	fillRect(gi.fullImage, image.Rect(panel.Min.X, panel.Min.Y, panel.Max.X, panel.Min.Y+l.line), theme.Grid)
+
+	enemyLeft := l.board(EnemySide).Min.X - l.labelWidth
+	columns := []struct {
+		area    image.Rectangle
+		entries []FleetEntry
+	}{
+		{image.Rect(panel.Min.X, panel.Min.Y, enemyLeft, panel.Max.Y), player},
+		{image.Rect(enemyLeft, panel.Min.Y, panel.Max.X, panel.Max.Y), enemy},
+	}
+
+	// Both columns use the scale at which the longest line fits
+	scale := 2 * l.scale
+	for _, column := range columns {
+		for _, line := range fleetLines(column.entries) {
+			if s := largestTextScale(line, image.Pt(column.area.Dx()-2*padding, rowH-4*l.scale), 2*l.scale); s < scale {
+				scale = s
+			}
+		}
+	}
+
+	for _, column := range columns {
+		fillRect(gi.fullImage, image.Rect(column.area.Min.X, panel.Min.Y, column.area.Min.X+l.line, panel.Max.Y), theme.Grid)
+
+		for i, line := range fleetLines(column.entries) {
+			c := theme.Label
+			if column.entries[i].Status == Damaged {
+				c = theme.Hit
+			}
+
+			size := textSize(line, scale)
+			center := image.Pt(column.area.Min.X+padding+size.X/2, panel.Min.Y+padding+i*rowH+rowH/2)
+			drawText(gi.fullImage, line, center, scale, c)
+
+			if column.entries[i].Status == Sunk {
+				strike := image.Rect(center.X-size.X/2, center.Y-scale/2, center.X+size.X/2, center.Y+scale-scale/2)
+				fillRect(gi.fullImage, strike, c)
+			}
+		}
+	}
+}
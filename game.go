@@ -37,6 +37,11 @@ type Game struct {
 	enemyShips          []ship
 	enemyVolleys        []volley
 	enemyBoard          [10][10]boardTile
+	moveOrder           []Side
+	subscribers         []subscriber
+	nextSubscriberID    int
+	loser               Side
+	finishReason        FinishReason
 }
 
 func newBoard() [10][10]boardTile {
@@ -66,6 +71,20 @@ func NewGame() Game {
 	return g
 }
 
+// clone returns a deep copy of the game. Copying a Game by value shares the ship and
+// volley slices, so any copy handed to another goroutine must be cloned first.
+func (g Game) clone() Game {
+	c := g
+	c.playerShips = append([]ship(nil), g.playerShips...)
+	c.playerVolleys = append([]volley(nil), g.playerVolleys...)
+	c.enemyShips = append([]ship(nil), g.enemyShips...)
+	c.enemyVolleys = append([]volley(nil), g.enemyVolleys...)
+	c.moveOrder = append([]Side(nil), g.moveOrder...)
+	c.subscribers = append([]subscriber(nil), g.subscribers...)
+
+	return c
+}
+
 // LoadPlayerShips loads the player ships from a string of positions.
 // The positions will be in the following format:
 // 	 pos[0] Aircraft Carrier Position + Direction
@@ -75,13 +94,18 @@ func NewGame() Game {
 //   pos[4] Destroyer Position + Direction
 // I.E. A1H;B8V;E3H;G3V;H8H
 func (g *Game) LoadPlayerShips(positions string) error {
-	var err error
+	before := g.eventState()
 
-	g.playerBoard, g.playerShips, err = g.getShipsFromPositions(g.playerBoard, positions)
+	// The board is only replaced once every ship is placed so a rejected placement can be retried
+	board, ships, err := g.getShipsFromPositions(g.playerBoard, positions)
 	if err != nil {
 		return fmt.Errorf("setting player positions: %w", err)
 	}
 
+	g.playerBoard, g.playerShips = board, ships
+
+	g.emitSince(before)
+
 	return nil
 }
 
@@ -94,13 +118,18 @@ func (g *Game) LoadPlayerShips(positions string) error {
 //   pos[4] Destroyer Position + Direction
 // I.E. A1H;B8V;E3H;G3V;H8H
 func (g *Game) LoadEnemyShips(positions string) error {
-	var err error
+	before := g.eventState()
 
-	g.enemyBoard, g.enemyShips, err = g.getShipsFromPositions(g.enemyBoard, positions)
+	// The board is only replaced once every ship is placed so a rejected placement can be retried
+	board, ships, err := g.getShipsFromPositions(g.enemyBoard, positions)
 	if err != nil {
 		return fmt.Errorf("settings enemy positions: %w", err)
 	}
 
+	g.enemyBoard, g.enemyShips = board, ships
+
+	g.emitSince(before)
+
 	return nil
 }
 
@@ -109,6 +138,10 @@ func (g Game) getShipsFromPositions(board [10][10]boardTile, positions string) (
 	var ships []ship
 	var err error
 
+	if len(pos) != 5 {
+		return [10][10]boardTile{}, []ship{}, fmt.Errorf("expected 5 ship positions but got %d", len(pos))
+	}
+
 	// Deal with the Aircraft Carrier
 	board, ships, err = g.addShip(board, ships, pos[0], shipAircraftCarrier)
 	if err != nil {
@@ -148,11 +181,11 @@ func (g Game) addShip(board [10][10]boardTile, ships []ship, position string, sh
 		return [10][10]boardTile{}, []ship{}, err
 	}
 
-	if direction == horizontal && x+getShipWidth(shipType) > 9 {
+	if direction == horizontal && x+getShipWidth(shipType) > 10 {
 		return [10][10]boardTile{}, []ship{}, fmt.Errorf("unable to place ship as ship extends off the board")
 	}
 
-	if direction == vertical && y+getShipWidth(shipType) > 9 {
+	if direction == vertical && y+getShipWidth(shipType) > 10 {
 		return [10][10]boardTile{}, []ship{}, fmt.Errorf("unable to place ship as ship extends off the board")
 	}
 
@@ -220,70 +253,102 @@ func (g Game) parsePosition(position string) (int, int, shipDirection, error) {
 // LoadPlayerVolleys will take a list of positions separated by a ; and will
 // load those into the current Game as the players volleys.
 func (g *Game) LoadPlayerVolleys(positions string) error {
-	var err error
-
 	if len(g.enemyShips) == 0 {
 		return fmt.Errorf("cannot place player volleys before placing enemy ships")
 	}
 
-	_, g.enemyBoard, g.playerVolleys, g.enemyShips, err = g.updateVolleysFromPositions(g.enemyBoard, g.playerVolleys, g.enemyShips, positions)
+	before := g.eventState()
+	_, board, volleys, ships, err := g.updateVolleysFromPositions(g.enemyBoard, g.playerVolleys, g.enemyShips, positions)
 	if err != nil {
 		return fmt.Errorf("setting player volleys: %w", err)
 	}
 
+	g.recordMoves(PlayerSide, len(volleys)-len(g.playerVolleys))
+	g.enemyBoard, g.playerVolleys, g.enemyShips = board, volleys, ships
+
+	g.checkAllShipsSunk()
+	g.emitSince(before)
+
 	return nil
 }
 
 // LoadEnemyVolleys will take a list of positions separated by a ; and will
 // load those into the current Game as the enemy volleys.
 func (g *Game) LoadEnemyVolleys(positions string) error {
-	var err error
-
 	if len(g.playerShips) == 0 {
 		return fmt.Errorf("cannot place enemy volleys before placing player ships")
 	}
 
-	_, g.playerBoard, g.enemyVolleys, g.playerShips, err = g.updateVolleysFromPositions(g.playerBoard, g.enemyVolleys, g.playerShips, positions)
+	before := g.eventState()
+	_, board, volleys, ships, err := g.updateVolleysFromPositions(g.playerBoard, g.enemyVolleys, g.playerShips, positions)
 	if err != nil {
 		return fmt.Errorf("setting enemy volleys: %w", err)
 	}
 
+	g.recordMoves(EnemySide, len(volleys)-len(g.enemyVolleys))
+	g.playerBoard, g.enemyVolleys, g.playerShips = board, volleys, ships
+
+	g.checkAllShipsSunk()
+	g.emitSince(before)
+
 	return nil
 }
 
 // PlayerVolley will execute a single player volley against a game. It will return
-// if the last volley was a hit, miss, or sunk a ship.
+// if the last volley was a hit, miss, or sunk a ship. Volleys at a tile that has
+// already been fired at and volleys once the game is finished are rejected.
 func (g *Game) PlayerVolley(position string) (string, error) {
-	var err error
-	var response string
+	if g.IsFinished() {
+		return "", fmt.Errorf("player volley: game is finished")
+	}
 
-	response, g.enemyBoard, g.playerVolleys, g.enemyShips, err = g.updateVolleysFromPositions(g.enemyBoard, g.playerVolleys, g.enemyShips, position)
+	before := g.eventState()
+	response, board, volleys, ships, err := g.updateVolleysFromPositions(g.enemyBoard, g.playerVolleys, g.enemyShips, position)
 	if err != nil {
 		return "", fmt.Errorf("update player volleys from positions: %w", err)
 	}
 
+	g.recordMoves(PlayerSide, len(volleys)-len(g.playerVolleys))
+	g.enemyBoard, g.playerVolleys, g.enemyShips = board, volleys, ships
+
+	g.checkAllShipsSunk()
+	g.emitSince(before)
+
 	return response, nil
 }
 
 // EnemyVolley will execute a single enemy volley against a game. It will return
-// if the last volley was a hit, miss, or sunk a ship.
+// if the last volley was a hit, miss, or sunk a ship. Volleys at a tile that has
+// already been fired at and volleys once the game is finished are rejected.
 func (g *Game) EnemyVolley(position string) (string, error) {
-	var err error
-	var response string
+	if g.IsFinished() {
+		return "", fmt.Errorf("enemy volley: game is finished")
+	}
 
-	response, g.playerBoard, g.enemyVolleys, g.playerShips, err = g.updateVolleysFromPositions(g.playerBoard, g.enemyVolleys, g.playerShips, position)
+	before := g.eventState()
+	response, board, volleys, ships, err := g.updateVolleysFromPositions(g.playerBoard, g.enemyVolleys, g.playerShips, position)
 	if err != nil {
 		return "", fmt.Errorf("update enemy volleys from positions: %w", err)
 	}
 
+	g.recordMoves(EnemySide, len(volleys)-len(g.enemyVolleys))
+	g.playerBoard, g.enemyVolleys, g.playerShips = board, volleys, ships
+
+	g.checkAllShipsSunk()
+	g.emitSince(before)
+
 	return response, nil
 }
 
 func (g Game) updateVolleysFromPositions(board [10][10]boardTile, volleys []volley, ships []ship, positions string) (string, [10][10]boardTile, []volley, []ship, error) {
 	pos := strings.Split(positions, ";")
-	response := "Miss"
+	response := ""
+
+	// The ships are copied so a failed volley does not leave hits on the game's ships
+	ships = append([]ship(nil), ships...)
 
 	for _, volleyPos := range pos {
+		response = "Miss"
 		parts := g.volleyPositionRegex.FindStringSubmatch(volleyPos)
 
 		if parts == nil || len(parts) != 3 {
@@ -304,37 +369,22 @@ func (g Game) updateVolleysFromPositions(board [10][10]boardTile, volleys []voll
 			return "", [10][10]boardTile{}, []volley{}, []ship{}, fmt.Errorf("unable to parse volley x position: value out of range")
 		}
 
+		if board[yPos][xPos].volleyIndex != -1 {
+			return "", [10][10]boardTile{}, []volley{}, []ship{}, fmt.Errorf("a volley has already been fired at %s", volleyPos)
+		}
+
 		board[yPos][xPos].volleyIndex = len(volleys)
 
 		vType := miss
-		for i, currentShip := range ships {
-			if currentShip.direction == horizontal {
-				if xPos >= currentShip.x && xPos <= currentShip.x+getShipWidth(currentShip.shipType) && yPos == currentShip.y {
-					ships[i].hits++
-					if ships[i].hits == ships[i].width {
-						response = fmt.Sprintf("You sunk my %s", shipType(i))
-					} else {
-						response = "Hit"
-					}
-
-					vType = hit
-					break
-				}
+		if shipIndex := board[yPos][xPos].shipIndex; shipIndex != -1 {
+			ships[shipIndex].hits++
+			if ships[shipIndex].hits == ships[shipIndex].width {
+				response = fmt.Sprintf("You sunk my %s", ships[shipIndex].shipType)
+			} else {
+				response = "Hit"
 			}
 
-			if currentShip.direction == vertical {
-				if yPos >= currentShip.y && yPos <= currentShip.y+getShipWidth(currentShip.shipType) && xPos == currentShip.x {
-					ships[i].hits++
-					if ships[i].hits == ships[i].width {
-						response = fmt.Sprintf("You sunk my %s", shipType(i))
-					} else {
-						response = "Hit"
-					}
-
-					vType = hit
-					break
-				}
-			}
+			vType = hit
 		}
 
 		volleys = append(volleys, volley{
@@ -365,30 +415,121 @@ func (g Game) GetShipMap() [2][10][10]int {
 	return shipMap
 }
 
-// GetVolleyMap will convert the volleys (as volley indexes) and put them into
+// GetVolleyMap will convert the volleys (as volley types) and put them into
 // a 2x10x10x map representing the player volleys [0] and the enemy volleys [1].
+// The player volleys are read from the enemy board they were fired at and the
+// enemy volleys from the player board, tiles that haven't been fired at are -1.
 func (g Game) GetVolleyMap() [2][10][10]int {
 	volleyMap := [2][10][10]int{}
 
+	// Player volleys land on the enemy board and enemy volleys land on the player board
 	for y := 0; y < 10; y++ {
 		for x := 0; x < 10; x++ {
-			if g.playerBoard[y][x].volleyIndex == -1 {
+			if g.enemyBoard[y][x].volleyIndex == -1 {
 				volleyMap[0][y][x] = -1
 				continue
 			}
 
-			volleyMap[0][y][x] = int(g.playerVolleys[g.playerBoard[y][x].volleyIndex].volleyType)
+			volleyMap[0][y][x] = int(g.playerVolleys[g.enemyBoard[y][x].volleyIndex].volleyType)
 		}
 
 		for x := 0; x < 10; x++ {
-			if g.enemyBoard[y][x].volleyIndex == -1 {
+			if g.playerBoard[y][x].volleyIndex == -1 {
 				volleyMap[1][y][x] = -1
 				continue
 			}
 
-			volleyMap[1][y][x] = int(g.enemyVolleys[g.enemyBoard[y][x].volleyIndex].volleyType)
+			volleyMap[1][y][x] = int(g.enemyVolleys[g.playerBoard[y][x].volleyIndex].volleyType)
 		}
 	}
 
 	return volleyMap
 }
+
+// Turn returns the side that is expected to act next. A side that has not placed its
+// ships yet is expected to do that first, after that the sides take turns firing
+// volleys starting with the player.
+func (g Game) Turn() Side {
+	if len(g.playerShips) == 0 {
+		return PlayerSide
+	}
+
+	if len(g.enemyShips) == 0 {
+		return EnemySide
+	}
+
+	if len(g.playerVolleys) > len(g.enemyVolleys) {
+		return EnemySide
+	}
+
+	return PlayerSide
+}
+
+// IsFinished returns true once the game has been won, forfeited, or timed out.
+func (g Game) IsFinished() bool {
+	return g.finishReason != NotFinished
+}
+
+// FinishReason returns why the game finished, or NotFinished if it is still being played.
+func (g Game) FinishReason() FinishReason {
+	return g.finishReason
+}
+
+// Winner returns the side that won the game. The second return value is false while
+// the game is still being played.
+func (g Game) Winner() (Side, bool) {
+	if !g.IsFinished() {
+		return PlayerSide, false
+	}
+
+	return g.loser.Opponent(), true
+}
+
+// Forfeit marks the game as finished with side as the loser. The reason must be either
+// Forfeited or TimedOut.
+func (g *Game) Forfeit(side Side, reason FinishReason) error {
+	if reason != Forfeited && reason != TimedOut {
+		return fmt.Errorf("forfeiting game: invalid reason %s", reason)
+	}
+
+	if g.IsFinished() {
+		return fmt.Errorf("forfeiting game: game is already finished")
+	}
+
+	before := g.eventState()
+	g.loser = side
+	g.finishReason = reason
+	g.emitSince(before)
+
+	return nil
+}
+
+func (g *Game) checkAllShipsSunk() {
+	if g.IsFinished() {
+		return
+	}
+
+	if allShipsSunk(g.enemyShips) {
+		g.loser = EnemySide
+		g.finishReason = AllShipsSunk
+	}
+
+	if allShipsSunk(g.playerShips) {
+		g.loser = PlayerSide
+		g.finishReason = AllShipsSunk
+	}
+}
+
+func allShipsSunk(ships []ship) bool {
+	if len(ships) == 0 {
+		return false
+	}
+
+	for _, s := range ships {
+		if s.hits < s.width {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,5 +1,9 @@
 module twittership
 
-go 1.14
+go 1.16
 
-require github.com/bloveless/tweetgo v0.0.0-20200509135615-c21d87416cce // indirect
+require (
+	github.com/bloveless/tweetgo v0.0.0-20200509135615-c21d87416cce // indirect
+	github.com/gorilla/websocket v1.4.2
+	golang.org/x/image v0.0.0-20210220032944-ac19c3e999fb
+)
@@ -0,0 +1,216 @@
+package twittership
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Heatmap is a value for every tile of a board, indexed by row then column like the boards.
+type Heatmap [10][10]float64
+
+// HeatmapOverlay is a heatmap drawn over one of the boards of a GameImage. Tiles are tinted
+// with the hit color of the theme, the higher the value the stronger the tint.
+type HeatmapOverlay struct {
+	Values Heatmap
+	// Board is the board the heatmap is drawn over, PlayerSide for the player board on the
+	// left and EnemySide for the enemy board on the right.
+	Board Side
+	// Title describes the values in the legend I.E. "Shots".
+	Title string
+}
+
+// heatmapMaxAlpha is the opacity of the tint on the tiles with the highest value, it stays
+// below fully opaque so the ships and volleys underneath can still be seen.
+const heatmapMaxAlpha = 200
+
+// hitPlacementWeight is how much more likely a ship placement is for every hit it covers
+// that doesn't belong to a sunk ship.
+const hitPlacementWeight = 20
+
+// Max returns the highest value in the heatmap.
+func (h Heatmap) Max() float64 {
+	max := 0.0
+	for y := range h {
+		for x := range h[y] {
+			if h[y][x] > max {
+				max = h[y][x]
+			}
+		}
+	}
+
+	return max
+}
+
+// ShotFrequency counts how often each tile of the opponent board was fired at by side
+// across every game provided, I.E. to find where a player likes to aim.
+func ShotFrequency(games []Game, side Side) Heatmap {
+	var h Heatmap
+
+	for _, g := range games {
+		for _, m := range g.Moves() {
+			if m.Side != side {
+				continue
+			}
+
+			h[m.Y][m.X]++
+		}
+	}
+
+	return h
+}
+
+// ProbabilityDensity scores every tile of the opponent board of side by how many ways the
+// opponent ships that haven't been sunk could cover it, using only the shots side has seen.
+// Ships can't be placed over misses or sunk ships, placements over hits of ships that
+// haven't been sunk are more likely, and tiles that have already been fired at score zero.
+func (g Game) ProbabilityDensity(side Side) Heatmap {
+	p := g.perspective(side)
+	board := p.enemyBoard
+
+	var widths []int
+	for _, s := range p.enemyShips {
+		if s.hits < s.width {
+			widths = append(widths, s.width)
+		}
+	}
+
+	// The sizes of the ships are known even before they have been placed
+	if len(p.enemyShips) == 0 {
+		for t := shipAircraftCarrier; t <= shipDestroyer; t++ {
+			widths = append(widths, getShipWidth(t))
+		}
+	}
+
+	blocked := func(x, y int) bool {
+		t := board[y][x]
+		if t.volleyIndex == -1 {
+			return false
+		}
+
+		return t.shipIndex == -1 || p.enemyShips[t.shipIndex].hits >= p.enemyShips[t.shipIndex].width
+	}
+
+	var h Heatmap
+	for _, width := range widths {
+		for _, direction := range []shipDirection{horizontal, vertical} {
+			for y := 0; y < 10; y++ {
+				for x := 0; x < 10; x++ {
+					tiles, ok := placementTiles(x, y, width, direction)
+					if !ok {
+						continue
+					}
+
+					weight := 1.0
+					for _, t := range tiles {
+						if blocked(t.X, t.Y) {
+							weight = 0
+							break
+						}
+
+						if board[t.Y][t.X].volleyIndex != -1 {
+							weight += hitPlacementWeight
+						}
+					}
+
+					for _, t := range tiles {
+						if board[t.Y][t.X].volleyIndex == -1 {
+							h[t.Y][t.X] += weight
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return h
+}
+
+// placementTiles returns the tiles a ship of width would cover at x, y and whether it fits
+// on the board.
+func placementTiles(x, y, width int, direction shipDirection) ([]image.Point, bool) {
+	tiles := make([]image.Point, 0, width)
+	for i := 0; i < width; i++ {
+		t := image.Pt(x+i, y)
+		if direction == vertical {
+			t = image.Pt(x, y+i)
+		}
+
+		if t.X > 9 || t.Y > 9 {
+			return nil, false
+		}
+
+		tiles = append(tiles, t)
+	}
+
+	return tiles, true
+}
+
+// heatColor returns the tint for a value, fully transparent for zero.
+func heatColor(theme Theme, value, max float64) color.NRGBA {
+	alpha := 0.0
+	if max > 0 && value > 0 {
+		alpha = value / max * heatmapMaxAlpha
+	}
+
+	return color.NRGBA{R: theme.Hit.R, G: theme.Hit.G, B: theme.Hit.B, A: uint8(alpha)}
+}
+
+// drawHeatmap tints the tiles of a board and draws the legend below the boards.
+func (gi GameImage) drawHeatmap(overlay HeatmapOverlay) {
+	ui := gi.playerImage
+	if overlay.Board == EnemySide {
+		ui = gi.enemyImage
+	}
+
+	max := overlay.Values.Max()
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			tint := image.NewUniform(heatColor(ui.theme, overlay.Values[y][x], max))
+			draw.Draw(ui.img, ui.tile(x, y).Add(ui.img.Rect.Min), tint, image.Point{}, draw.Over)
+		}
+	}
+
+	gi.drawHeatmapLegend(overlay.Title, max)
+}
+
+// drawHeatmapLegend draws the title followed by a scale from zero to the highest value.
+func (gi GameImage) drawHeatmapLegend(title string, max float64) {
+	l := gi.layout
+	theme := gi.playerImage.theme
+	legend := l.legend()
+	padding := panelPadding * l.scale / 2
+
+	fillRect(gi.fullImage, legend, theme.Background)
+	fillRect(gi.fullImage, image.Rect(legend.Min.X, legend.Min.Y, legend.Max.X, legend.Min.Y+l.line), theme.Grid)
+
+	row := image.Rect(legend.Min.X+padding, legend.Min.Y+padding, legend.Max.X-padding, legend.Max.Y-padding)
+	low, high := "0", fmt.Sprintf("%.4g", max)
+	scale := largestTextScale(title+" "+low+" "+high, image.Pt(row.Dx()/2, row.Dy()-4*l.scale), 2*l.scale)
+
+	left := row.Min.X
+	for _, text := range []string{title, low} {
+		if text == "" {
+			continue
+		}
+
+		size := textSize(text, scale)
+		drawText(gi.fullImage, text, image.Pt(left+size.X/2, row.Min.Y+row.Dy()/2), scale, theme.Label)
+		left += size.X + padding
+	}
+
+	highSize := textSize(high, scale)
+	drawText(gi.fullImage, high, image.Pt(row.Max.X-highSize.X/2, row.Min.Y+row.Dy()/2), scale, theme.Label)
+
+	// The bar blends from water to the strongest tint the same way the tiles are tinted
+	bar := image.Rect(left, row.Min.Y+row.Dy()/4, row.Max.X-highSize.X-padding, row.Max.Y-row.Dy()/4)
+	fillRect(gi.fullImage, bar, theme.Grid)
+	bar = bar.Inset(l.line)
+	fillRect(gi.fullImage, bar, theme.Water)
+
+	for x := bar.Min.X; x < bar.Max.X; x++ {
+		tint := image.NewUniform(heatColor(theme, float64(x-bar.Min.X+1), float64(bar.Dx())))
+		draw.Draw(gi.fullImage, image.Rect(x, bar.Min.Y, x+1, bar.Max.Y), tint, image.Point{}, draw.Over)
+	}
+}
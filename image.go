@@ -1,24 +1,65 @@
 package twittership
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
-	"image/png"
-	"os"
+	"io"
 )
 
+// ErrShotNotInGame is returned when applying a shot to a game image that isn't one of the moves
+// of the game.
+var ErrShotNotInGame = errors.New("shot is not a move of the game")
+
 type userImage struct {
-	height     int
-	width      int
-	tileHeight int
-	tileWidth  int
-	img        *image.RGBA
+	layout
+	theme   Theme
+	sprites *SpriteSheet
+	img     *image.RGBA
+}
+
+// ImageOptions configures how a GameImage is drawn.
+type ImageOptions struct {
+	// Template is drawn behind the boards. When it is nil the frame, headers and coordinate
+	// labels are drawn using the theme colors instead. Only the embedded template and
+	// template files loaded by path are drawn once and reused, any other template is drawn
+	// for every image.
+	Template image.Image
+	// Theme selects the colors and glyphs. The zero value uses ClassicTheme.
+	Theme Theme
+	// Sprites draws the ships using sprites instead of flat rectangles when it is set. Hit
+	// segments are drawn with the damaged sprites. See DefaultSpriteSheet.
+	Sprites *SpriteSheet
+	// FleetStatus adds a panel below the boards listing every ship as afloat, damaged or sunk.
+	FleetStatus bool
+	// Scale multiplies every size in the image, including the line widths and the text, so
+	// a scale of 2 or 3 draws sharp images for high DPI screens. It defaults to 1.
+	Scale int
+	// CanvasWidth and CanvasHeight draw the image at exactly this size instead of using the
+	// board size. The tiles are made as big as possible, and may not be square, and the
+	// space left over is split evenly around the boards.
+	CanvasWidth  int
+	CanvasHeight int
+	// Heatmap is drawn over one of the boards with a legend below the boards when it is set.
+	Heatmap *HeatmapOverlay
+	// HighlightLastMoves outlines the most recent shot of each side in the highlight color.
+	HighlightLastMoves bool
+	// ShotNumbers draws the number of the move in the corner of every tile that was fired at.
+	ShotNumbers bool
+	// Caption adds a banner below the boards describing the most recent shot I.E.
+	// "Enemy fired at C4 - Hit!".
+	Caption bool
+	// Spectator hides the player ships the same way as the enemy ships, so only the hits,
+	// misses and sunk ships on either board are shown.
+	Spectator bool
 }
 
 // GameImage stores the current information for the game image
 type GameImage struct {
+	layout      layout
+	opts        ImageOptions
 	fullImage   *image.RGBA
 	playerImage userImage
 	enemyImage  userImage
@@ -26,13 +67,83 @@ type GameImage struct {
 
 // NewGameImageFromGame will create a new game image from a game. There is no validation when converting
 // a game image to a game because the validation is assume to have happened when creating the game.
+// The template is the path to the board template image, if it is empty the template embedded in
+// the package is used. The decoded template is reused until the file changes.
 func NewGameImageFromGame(g Game, h, w int, template string) (GameImage, error) {
-	gi, err := newGameImage(h, w, template)
+	if template == "" {
+		tmpl, err := DefaultTemplate()
+		if err != nil {
+			return GameImage{}, fmt.Errorf("unable to create new game image: %w", err)
+		}
+
+		return NewGameImageFromTemplate(g, h, w, tmpl)
+	}
+
+	tmpl, err := loadTemplateFile(template)
 	if err != nil {
-		return GameImage{}, fmt.Errorf("unable to create new game image: %w", err)
+		return GameImage{}, err
 	}
 
-	for _, playerShip := range g.playerShips {
+	return NewGameImageFromTemplate(g, h, w, tmpl)
+}
+
+// NewGameImageFromReader will create a new game image from a game using the board template
+// image read from r.
+func NewGameImageFromReader(g Game, h, w int, r io.Reader) (GameImage, error) {
+	tmpl, _, err := image.Decode(r)
+	if err != nil {
+		return GameImage{}, fmt.Errorf("decoding game_template: %w", err)
+	}
+
+	return NewGameImageFromTemplate(g, h, w, tmpl)
+}
+
+// NewGameImageFromTemplate will create a new game image from a game drawn on top of the board
+// template image. If the template is nil the frame, headers and coordinate labels are drawn
+// without a template.
+func NewGameImageFromTemplate(g Game, h, w int, template image.Image) (GameImage, error) {
+	return NewGameImageWithOptions(g, h, w, ImageOptions{Template: template})
+}
+
+// NewGameImageWithOptions will create a new game image from a game drawn using the options provided.
+// The board size is ignored when the options have a canvas size.
+func NewGameImageWithOptions(g Game, h, w int, opts ImageOptions) (GameImage, error) {
+	if opts.Theme.Name == "" {
+		opts.Theme = ClassicTheme
+	}
+
+	l := newLayout(h, w, opts.Scale, newLayoutPanels(opts))
+	if opts.CanvasWidth != 0 || opts.CanvasHeight != 0 {
+		var err error
+		l, err = newCanvasLayout(opts.CanvasWidth, opts.CanvasHeight, opts.Scale, newLayoutPanels(opts))
+		if err != nil {
+			return GameImage{}, fmt.Errorf("unable to create new game image: %w", err)
+		}
+	}
+
+	gi := newGameImage(l, opts)
+	gi.drawGame(g)
+
+	return gi, nil
+}
+
+// drawGame draws the ships, volleys and everything else the options add on top of the empty
+// boards.
+func (gi GameImage) drawGame(g Game) {
+	opts := gi.opts
+
+	// Spectators only see the sunk ships, which are drawn after the volleys
+	visibleShips := g.playerShips
+	if opts.Spectator {
+		visibleShips = nil
+	}
+
+	for _, playerShip := range visibleShips {
+		if gi.playerImage.sprites != nil {
+			gi.playerImage.placeShipSprites(playerShip, g.playerBoard)
+			continue
+		}
+
 		switch playerShip.shipType {
 		case shipAircraftCarrier:
 			gi.playerImage.placeAircraftCarrier(playerShip.x, playerShip.y, playerShip.direction)
@@ -59,76 +170,97 @@ func NewGameImageFromGame(g Game, h, w int, template string) (GameImage, error)
 	for _, enemyVolley := range g.enemyVolleys {
 		switch enemyVolley.volleyType {
 		case hit:
+			if gi.playerImage.sprites != nil && !opts.Spectator {
+				// The damaged sprite already shows the hit so only the glyph is drawn over it
+				gi.playerImage.drawVolley(enemyVolley.x, enemyVolley.y, hit, false)
+				continue
+			}
+
 			gi.playerImage.drawHit(enemyVolley.x, enemyVolley.y)
 		case miss:
 			gi.playerImage.drawMiss(enemyVolley.x, enemyVolley.y)
 		}
 	}
 
-	return gi, nil
-}
+	// Sunk ships are drawn on both boards, revealing the full outline of sunk enemy ships
+	for _, playerShip := range g.playerShips {
+		if playerShip.hits >= playerShip.width {
+			gi.playerImage.drawSunkShip(playerShip)
+		}
+	}
 
-// NewGameImage will create a battleship gameboard with a background. The GameImage returned represents
-// both the player image, and the enemy image.
-func newGameImage(h, w int, template string) (GameImage, error) {
-	totalW, totalH := w*2+80, h+90
-	gi := GameImage{
-		fullImage: image.NewRGBA(image.Rect(0, 0, totalW, totalH)),
-		playerImage: userImage{
-			height:     h,
-			width:      w,
-			tileHeight: h / 10,
-			tileWidth:  w / 10,
-		},
-		enemyImage: userImage{
-			height:     h,
-			width:      w,
-			tileHeight: h / 10,
-			tileWidth:  w / 10,
-		},
-	}
-
-	f, err := os.Open(template)
-	if err != nil {
-		return GameImage{}, fmt.Errorf("opening game_template: %w", err)
+	for _, enemyShip := range g.enemyShips {
+		if enemyShip.hits >= enemyShip.width {
+			gi.enemyImage.drawSunkShip(enemyShip)
+		}
 	}
 
-	gameTemplateImg, _, err := image.Decode(f)
-	if err != nil {
-		return GameImage{}, fmt.Errorf("decoding game_template: %w", err)
+	if opts.FleetStatus {
+		gi.drawFleetPanel(g.FleetStatus(PlayerSide, opts.Spectator), g.FleetStatus(EnemySide, true))
 	}
 
-	draw.Draw(gi.fullImage, image.Rect(0, 0, totalW, totalH), gameTemplateImg, image.Pt(0, 0), draw.Over)
+	if opts.Heatmap != nil {
+		gi.drawHeatmap(*opts.Heatmap)
+	}
 
-	gi.playerImage.img = gi.fullImage.SubImage(image.Rect(40, 90, w+80, totalH)).(*image.RGBA)
-	gi.playerImage.drawBackground()
+	moves := g.Moves()
+	if opts.HighlightLastMoves {
+		for _, side := range []Side{PlayerSide, EnemySide} {
+			if m, ok := lastMoveBy(moves, side); ok {
+				gi.highlightMove(m)
+			}
+		}
+	}
 
-	gi.enemyImage.img = gi.fullImage.SubImage(image.Rect(w+80, 90, totalW, totalH)).(*image.RGBA)
-	gi.enemyImage.drawBackground()
+	if opts.ShotNumbers {
+		for _, m := range moves {
+			gi.drawShotNumber(m)
+		}
+	}
 
-	return gi, nil
+	if opts.Caption {
+		text := "No shots have been fired"
+		if len(moves) > 0 {
+			text = moveCaption(moves[len(moves)-1])
+		}
+
+		gi.drawCaption(text)
+	}
 }
 
+// newGameImage will create a battleship gameboard with a background. The GameImage returned represents
+// both the player image, and the enemy image. The background is copied from a cached base layer
+// so it is only drawn once for each layout, theme and template.
+func newGameImage(l layout, opts ImageOptions) GameImage {
+	// Every size in the theme is scaled with the rest of the image
+	theme := opts.Theme
+	theme.LineWidth *= l.scale
+	theme.GlyphPadding *= l.scale
+
+	gi := GameImage{
+		layout:      l,
+		opts:        opts,
+		fullImage:   image.NewRGBA(l.canvas),
+		playerImage: userImage{layout: l, theme: theme, sprites: opts.Sprites},
+		enemyImage:  userImage{layout: l, theme: theme, sprites: opts.Sprites},
+	}
+
+	copy(gi.fullImage.Pix, baseLayer(l, theme, opts.Template).Pix)
+
+	gi.playerImage.img = gi.fullImage.SubImage(l.board(PlayerSide)).(*image.RGBA)
+	gi.enemyImage.img = gi.fullImage.SubImage(l.board(EnemySide)).(*image.RGBA)
+
+	return gi
+}
+
+// drawBackground fills the board with water and draws the grid lines around every tile.
 func (ui userImage) drawBackground() {
-	for x := 0; x < ui.width; x++ {
-		for y := 0; y < ui.height; y++ {
-			if y%ui.tileHeight == 0 || x%ui.tileWidth == 0 {
-				c := color.RGBA{
-					R: 0,
-					G: 0,
-					B: 0,
-					A: 255,
-				}
-				ui.img.Set(ui.img.Rect.Min.X+x, ui.img.Rect.Min.Y+y, c)
-			} else {
-				c := color.RGBA{
-					R: 255,
-					G: 255,
-					B: 255,
-					A: 255,
-				}
-				ui.img.Set(ui.img.Rect.Min.X+x, ui.img.Rect.Min.Y+y, c)
-			}
+	board := ui.img.Rect
+	fillRect(ui.img, board, ui.theme.Grid)
+
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			fillRect(ui.img, ui.tile(x, y).Add(board.Min), ui.theme.Water)
 		}
 	}
 }
@@ -159,122 +291,198 @@ func (ui userImage) placeDestroyer(x, y int, direction shipDirection) {
 }
 
 func (ui userImage) drawShip(x, y, width int, direction shipDirection) {
-	startX := x * ui.tileWidth
-	startY := y * ui.tileWidth
-	endX := startX + width*ui.tileWidth
-	endY := startY + 1*ui.tileHeight
-
-	if direction == vertical {
-		endX = startX + 1*ui.tileWidth
-		endY = startY + width*ui.tileHeight
-	}
-
-	for x := 0; x < endX-startX; x++ {
-		for y := 0; y < endY-startY; y++ {
-			if y%ui.tileHeight != 0 && x%ui.tileWidth != 0 {
-				ui.img.Set(ui.img.Rect.Min.X+startX+x, ui.img.Rect.Min.Y+startY+y, color.RGBA{
-					R: 49,
-					G: 83,
-					B: 123,
-					A: 255,
-				})
-			}
+	tiles, _ := placementTiles(x, y, width, direction)
+	for _, t := range tiles {
+		fillRect(ui.img, ui.tile(t.X, t.Y).Add(ui.img.Rect.Min), ui.theme.Ship)
+	}
+}
+
+// placeShipSprites draws every segment of a ship using the sprite sheet. Segments that have
+// been hit are drawn with the damaged sprites.
+func (ui userImage) placeShipSprites(s ship, board [10][10]boardTile) {
+	for i := 0; i < s.width; i++ {
+		x, y := s.x+i, s.y
+		if s.direction == vertical {
+			x, y = s.x, s.y+i
 		}
+
+		ui.drawSprite(ui.sprites, s, i, x, y, board[y][x].volleyIndex != -1)
+	}
+}
+
+// drawSunkShip fills every tile of a sunk ship with the sunk color and outlines the ship.
+func (ui userImage) drawSunkShip(s ship) {
+	for i := 0; i < s.width; i++ {
+		x, y := s.x+i, s.y
+		if s.direction == vertical {
+			x, y = s.x, s.y+i
+		}
+
+		fillRect(ui.img, ui.tile(x, y).Add(ui.img.Rect.Min), ui.theme.Sunk)
+
+		if ui.sprites != nil {
+			ui.drawSprite(ui.sprites, s, i, x, y, true)
+		}
+
+		ui.drawVolley(x, y, hit, false)
+	}
+
+	tilesX, tilesY := s.width, 1
+	if s.direction == vertical {
+		tilesX, tilesY = 1, s.width
+	}
+
+	outline := image.Rect(
+		s.x*ui.tileWidth,
+		s.y*ui.tileHeight,
+		(s.x+tilesX)*ui.tileWidth+ui.line,
+		(s.y+tilesY)*ui.tileHeight+ui.line,
+	).Add(ui.img.Rect.Min)
+
+	thickness := 2 * ui.scale
+	fillRect(ui.img, image.Rect(outline.Min.X, outline.Min.Y, outline.Max.X, outline.Min.Y+thickness), ui.theme.Label)
+	fillRect(ui.img, image.Rect(outline.Min.X, outline.Max.Y-thickness, outline.Max.X, outline.Max.Y), ui.theme.Label)
+	fillRect(ui.img, image.Rect(outline.Min.X, outline.Min.Y, outline.Min.X+thickness, outline.Max.Y), ui.theme.Label)
+	fillRect(ui.img, image.Rect(outline.Max.X-thickness, outline.Min.Y, outline.Max.X, outline.Max.Y), ui.theme.Label)
+}
+
+// highlightTile draws a border around the inside of a tile.
+func (ui userImage) highlightTile(x, y int, c color.Color) {
+	tile := ui.tile(x, y).Add(ui.img.Rect.Min)
+
+	thickness := ui.theme.LineWidth
+	fillRect(ui.img, image.Rect(tile.Min.X, tile.Min.Y, tile.Max.X, tile.Min.Y+thickness), c)
+	fillRect(ui.img, image.Rect(tile.Min.X, tile.Max.Y-thickness, tile.Max.X, tile.Max.Y), c)
+	fillRect(ui.img, image.Rect(tile.Min.X, tile.Min.Y, tile.Min.X+thickness, tile.Max.Y), c)
+	fillRect(ui.img, image.Rect(tile.Max.X-thickness, tile.Min.Y, tile.Max.X, tile.Max.Y), c)
+}
+
+// highlightMove outlines the tile a move was fired at. Moves made by the player are on the
+// enemy board and moves made by the enemy are on the player board.
+func (gi GameImage) highlightMove(m Move) {
+	board := gi.enemyImage
+	if m.Side == EnemySide {
+		board = gi.playerImage
 	}
+
+	board.highlightTile(m.X, m.Y, board.theme.Highlight)
 }
 
 // DrawHit draws a hit mark on the game image
 func (ui userImage) drawHit(x, y int) {
-	ui.drawVolley(x, y, hit)
+	ui.drawVolley(x, y, hit, true)
 }
 
 // DrawMiss draws a miss mark on the game image
 func (ui userImage) drawMiss(x, y int) {
-	ui.drawVolley(x, y, miss)
+	ui.drawVolley(x, y, miss, true)
 }
 
-func (ui userImage) drawVolley(x, y int, volley volleyType) {
-	startX := x * ui.tileWidth
-	startY := y * ui.tileWidth
-	endX := startX + ui.tileWidth
-	endY := startY + ui.tileHeight
+// drawVolley draws the glyph for a volley on a tile. When fill is true the rest of the tile
+// is filled with the hit or miss color, otherwise whatever is on the tile is left visible.
+func (ui userImage) drawVolley(x, y int, volley volleyType, fill bool) {
+	bgColor := ui.theme.Miss
+	glyph := ui.theme.MissGlyph
 
-	bgColor := color.RGBA{
-		R: 200,
-		G: 200,
-		B: 200,
-		A: 255,
+	if volley == hit {
+		bgColor = ui.theme.Hit
+		glyph = ui.theme.HitGlyph
 	}
 
-	if volley == hit {
-		bgColor = color.RGBA{
-			R: 255,
-			G: 54,
-			B: 51,
-			A: 255,
-		}
+	if fill {
+		fillRect(ui.img, ui.tile(x, y).Add(ui.img.Rect.Min), bgColor)
 	}
 
-	xWidth := endX - startX
-	yWidth := endY - startY
+	// The mask covers the whole tile including the grid lines at the top and left
+	tile := image.Rect(x*ui.tileWidth, y*ui.tileHeight, (x+1)*ui.tileWidth, (y+1)*ui.tileHeight).Add(ui.img.Rect.Min)
+	mask := ui.theme.glyphMask(glyph, tile.Size())
+	draw.DrawMask(ui.img, tile, image.NewUniform(ui.theme.Glyph), image.Point{}, mask, image.Point{}, draw.Over)
+}
 
-	for x := 0; x < xWidth; x++ {
-		for y := 0; y < yWidth; y++ {
-			if ui.isPointOnX(x, y, xWidth, yWidth) {
-				ui.img.Set(ui.img.Rect.Min.X+startX+x, ui.img.Rect.Min.Y+startY+y, color.RGBA{
-					R: 100,
-					G: 100,
-					B: 100,
-					A: 255,
-				})
+// ApplyShot updates the image for the move m, where g is the game after the move was made.
+// Only the tiles the move changed and the annotations about the newest move are redrawn which
+// is much faster than drawing a new image for every move. Images with a heatmap are redrawn
+// completely as every shot changes the heatmap.
+func (gi GameImage) ApplyShot(g Game, m Move) error {
+	moves := g.Moves()
+	if m.Number < 1 || m.Number > len(moves) || moves[m.Number-1] != m {
+		return fmt.Errorf("%w: %d. %s", ErrShotNotInGame, m.Number, m.Position)
+	}
 
-				continue
-			}
+	if gi.opts.Heatmap != nil {
+		copy(gi.fullImage.Pix, baseLayer(gi.layout, gi.playerImage.theme, gi.opts.Template).Pix)
+		gi.drawGame(g)
 
-			if y%ui.tileHeight != 0 && x%ui.tileWidth != 0 {
-				ui.img.Set(ui.img.Rect.Min.X+startX+x, ui.img.Rect.Min.Y+startY+y, bgColor)
-			}
-		}
+		return nil
 	}
-}
 
-// isPointOnX is used when drawing the X image on the game board to indicate a volley.
-// It will determine the the current pixel is anywhere on the X and need to be filled in.
-func (ui userImage) isPointOnX(x, y, xWidth, yWidth int) bool {
-	thickness := 3
+	ui, board, ships := gi.enemyImage, g.enemyBoard, g.enemyShips
+	if m.Side == EnemySide {
+		ui, board, ships = gi.playerImage, g.playerBoard, g.playerShips
+	}
+
+	// Ships are only drawn on the player board and not at all for spectators
+	ownShips := m.Side == EnemySide && !gi.opts.Spectator
+	redrawn := ui.drawShot(board, ships, m.X, m.Y, ownShips)
 
-	// padding around the X axis on the X
-	if x < 5 || x > xWidth-5 {
-		return false
+	if gi.opts.HighlightLastMoves {
+		// The previous shot of the same side loses its highlight
+		if previous, ok := lastMoveBy(moves[:m.Number-1], m.Side); ok {
+			redrawn = append(redrawn, ui.drawShot(board, ships, previous.X, previous.Y, ownShips)...)
+		}
+
+		gi.highlightMove(m)
 	}
 
-	// padding around the Y axis on the Y
-	if y < 5 || y > yWidth-5 {
-		return false
+	if gi.opts.ShotNumbers {
+		for _, shot := range moves[:m.Number] {
+			for _, t := range redrawn {
+				if shot.Side == m.Side && shot.X == t.X && shot.Y == t.Y {
+					gi.drawShotNumber(shot)
+				}
+			}
+		}
 	}
 
-	// Is the point on the X
-	if (x > y-thickness && x < y+thickness) || (x > yWidth-y-thickness && x < yWidth-y+thickness) {
-		return true
+	if gi.opts.FleetStatus && m.Hit {
+		gi.drawFleetPanel(g.FleetStatus(PlayerSide, gi.opts.Spectator), g.FleetStatus(EnemySide, true))
 	}
 
-	return false
+	if gi.opts.Caption {
+		gi.drawCaption(moveCaption(moves[len(moves)-1]))
+	}
+
+	return nil
 }
 
-// WriteImage will write a PNG to the disk at the location provided by filename.
-func (gi GameImage) WriteImage(filename string) error {
-	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE, 0600)
-	if err != nil {
-		return fmt.Errorf("unable to write game board image: %w", err)
+// drawShot redraws the tile a volley was fired at as it is in the game, a sunk ship is
+// redrawn completely. Ships that haven't sunk are only drawn when ownShips is true, the same
+// as a new image. It returns every tile that was redrawn.
+func (ui userImage) drawShot(board [10][10]boardTile, ships []ship, x, y int, ownShips bool) []image.Point {
+	t := board[y][x]
+	if t.shipIndex == -1 {
+		ui.drawMiss(x, y)
+
+		return []image.Point{{X: x, Y: y}}
 	}
-	defer f.Close()
 
-	err = png.Encode(f, gi.fullImage)
-	if err != nil {
-		return fmt.Errorf("unable to encode game board image to png: %w", err)
+	s := ships[t.shipIndex]
+	if s.hits >= s.width {
+		ui.drawSunkShip(s)
+		tiles, _ := placementTiles(s.x, s.y, s.width, s.direction)
+
+		return tiles
 	}
 
-	return nil
+	if ownShips && ui.sprites != nil {
+		fillRect(ui.img, ui.tile(x, y).Add(ui.img.Rect.Min), ui.theme.Water)
+		ui.drawSprite(ui.sprites, s, x-s.x+y-s.y, x, y, true)
+		ui.drawVolley(x, y, hit, false)
+	} else {
+		ui.drawHit(x, y)
+	}
+
+	return []image.Point{{X: x, Y: y}}
 }
 
 // GetFullImage returns the image.RGBA fullImage. This is mostly used for decoupling the
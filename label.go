@@ -0,0 +1,143 @@
+package twittership
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// labelFace is the bitmap font used for every label drawn on the game images.
+var labelFace = basicfont.Face7x13
+
+// textSize returns the size of text drawn with the label font at scale.
+func textSize(text string, scale int) image.Point {
+	width := font.MeasureString(labelFace, text).Ceil()
+
+	return image.Pt(width*scale, labelFace.Height*scale)
+}
+
+// drawText draws text centred on center. The bitmap font is drawn at its natural size and
+// then scaled up by whole pixels so it stays crisp at every scale.
+func drawText(dst draw.Image, text string, center image.Point, scale int, c color.Color) {
+	if scale < 1 {
+		scale = 1
+	}
+
+	size := textSize(text, 1)
+	src := image.NewAlpha(image.Rect(0, 0, size.X, size.Y))
+	d := font.Drawer{
+		Dst:  src,
+		Src:  image.Opaque,
+		Face: labelFace,
+		Dot:  fixed.P(0, labelFace.Ascent),
+	}
+	d.DrawString(text)
+
+	origin := center.Sub(image.Pt(size.X*scale/2, size.Y*scale/2))
+	fill := image.NewUniform(c)
+
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			if src.AlphaAt(x, y).A == 0 {
+				continue
+			}
+
+			px := image.Rect(x*scale, y*scale, (x+1)*scale, (y+1)*scale).Add(origin)
+			draw.Draw(dst, px, fill, image.Point{}, draw.Over)
+		}
+	}
+}
+
+// largestTextScale returns the largest scale at which text fits within size.
+func largestTextScale(text string, size image.Point, maxScale int) int {
+	natural := textSize(text, 1)
+
+	scale := maxScale
+	for scale > 1 && (natural.X*scale > size.X || natural.Y*scale > size.Y) {
+		scale--
+	}
+
+	return scale
+}
+
+const (
+	tableTitleHeight = 60
+	tableRowHeight   = 36
+	tablePadding     = 12
+)
+
+// newTitledImage creates an image of the size provided with the title drawn in a band across
+// the top.
+func newTitledImage(width, height int, title string, theme Theme) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, img.Rect, theme.Background)
+
+	band := image.Rect(0, 0, width, tableTitleHeight)
+	fillRect(img, band, theme.Header)
+	drawText(img, title, centerOf(band), largestTextScale(title, band.Inset(tablePadding).Size(), 3), theme.Label)
+
+	return img
+}
+
+// drawTable draws rows of text in area, each left aligned in columns taking the fractions of
+// the width in widths. Every cell uses the largest scale at which the longest cell fits and
+// cellColor picks the color of each cell.
+func drawTable(img *image.RGBA, area image.Rectangle, rows [][]string, widths []float64, theme Theme, cellColor func(row, column int, cell string) color.RGBA) {
+	scale := 2
+	for _, row := range rows {
+		for i, cell := range row {
+			size := image.Pt(int(widths[i]*float64(area.Dx()))-tablePadding, tableRowHeight-8)
+			if s := largestTextScale(cell, size, 2); s < scale {
+				scale = s
+			}
+		}
+	}
+
+	for r, row := range rows {
+		top := area.Min.Y + r*tableRowHeight
+		fillRect(img, image.Rect(area.Min.X, top, area.Max.X, top+1), theme.Grid)
+
+		x := area.Min.X
+		for i, cell := range row {
+			size := textSize(cell, scale)
+			drawText(img, cell, image.Pt(x+tablePadding+size.X/2, top+tableRowHeight/2), scale, cellColor(r, i, cell))
+			x += int(widths[i] * float64(area.Dx()))
+		}
+	}
+}
+
+// textTable lays rows out in columns separated by two spaces. Columns are padded to the
+// widest cell, on the left when right is true for the column.
+func textTable(rows [][]string, right []bool) string {
+	widths := make([]int, len(right))
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			if right[i] {
+				cells[i] = fmt.Sprintf("%*s", widths[i], cell)
+			} else {
+				cells[i] = fmt.Sprintf("%-*s", widths[i], cell)
+			}
+		}
+
+		b.WriteString(strings.TrimRight(strings.Join(cells, "  "), " "))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,203 @@
+package twittership
+
+import (
+	"errors"
+	"image"
+)
+
+// ErrCanvasTooSmall is returned when the canvas size requested for an image is too small to
+// fit the boards.
+var ErrCanvasTooSmall = errors.New("canvas is too small to draw the boards")
+
+// minTileSize is the smallest tile, at a scale of 1, that can be drawn on a canvas.
+const minTileSize = 10
+
+// panelPadding is the space, at a scale of 1, around each panel below the boards.
+const panelPadding = 20
+
+// fleetPanelRows is the number of rows in the fleet status panel, one for each ship.
+const fleetPanelRows = fleetSize
+
+// captionHeight is the height, at a scale of 1, of the caption banner below the boards.
+const captionHeight = 40
+
+// layoutPanels are the optional panels drawn below the boards.
+type layoutPanels struct {
+	fleet   bool
+	legend  bool
+	caption bool
+}
+
+func newLayoutPanels(opts ImageOptions) layoutPanels {
+	return layoutPanels{fleet: opts.FleetStatus, legend: opts.Heatmap != nil, caption: opts.Caption}
+}
+
+// layout is where everything on a game image is drawn. Every size is in pixels and already
+// multiplied by the scale.
+type layout struct {
+	scale int
+	// line is the thickness of the grid lines
+	line       int
+	tileWidth  int
+	tileHeight int
+	// labelWidth is the width of the row letters beside each board
+	labelWidth int
+	// headerHeight is the height of the band with the board titles
+	headerHeight int
+	// numbersHeight is the height of the column numbers above each board
+	numbersHeight int
+	// panelHeight is the height of the fleet status panel below the boards, if any
+	panelHeight int
+	// legendHeight is the height of the heatmap legend below the fleet status panel, if any
+	legendHeight int
+	// captionHeight is the height of the caption banner at the bottom, if any
+	captionHeight int
+	canvas        image.Rectangle
+	// origin is the top left of the frame, any space left over on the canvas is split
+	// evenly around the frame
+	origin image.Point
+}
+
+// newLayout creates the layout for boards of w by h pixels, before they are scaled. The tiles
+// are a tenth of the board size and the board is made exactly big enough for the tiles and the grid
+// lines around them, so sizes that aren't a multiple of ten still draw whole tiles.
+func newLayout(h, w, scale int, panels layoutPanels) layout {
+	if scale < 1 {
+		scale = 1
+	}
+
+	l := newLayoutFromTiles(w/10*scale, h/10*scale, scale, panels)
+	l.canvas = image.Rect(0, 0, l.frameSize().X, l.frameSize().Y)
+
+	return l
+}
+
+// newCanvasLayout creates the layout with the largest tiles that fit on a canvas of width by
+// height pixels. The tiles do not need to be square.
+func newCanvasLayout(width, height, scale int, panels layoutPanels) (layout, error) {
+	if scale < 1 {
+		scale = 1
+	}
+
+	l := newLayoutFromTiles(0, 0, scale, layoutPanels{})
+
+	// Each half of the canvas is a label column and a board of ten tiles. Vertically it is
+	// the header, the numbers and a board, plus every panel which has padding and rows
+	// that are each 3/4 of a tile high.
+	rows, paddings := 0, 0
+	if panels.fleet {
+		rows, paddings = rows+fleetPanelRows, paddings+1
+	}
+
+	if panels.legend {
+		rows, paddings = rows+1, paddings+1
+	}
+
+	fixed := l.headerHeight + l.numbersHeight + l.line + paddings*panelPadding*scale
+	if panels.caption {
+		fixed += captionHeight * scale
+	}
+
+	tileWidth := (width/2 - l.labelWidth - l.line) / 10
+	tileHeight := (height - fixed) * 4 / (40 + 3*rows)
+
+	if tileWidth < minTileSize*scale || tileHeight < minTileSize*scale {
+		return layout{}, ErrCanvasTooSmall
+	}
+
+	l = newLayoutFromTiles(tileWidth, tileHeight, scale, panels)
+	l.canvas = image.Rect(0, 0, width, height)
+	l.origin = l.canvas.Size().Sub(l.frameSize()).Div(2)
+
+	return l, nil
+}
+
+func newLayoutFromTiles(tileWidth, tileHeight, scale int, panels layoutPanels) layout {
+	l := layout{
+		scale:         scale,
+		line:          scale,
+		tileWidth:     tileWidth,
+		tileHeight:    tileHeight,
+		labelWidth:    40 * scale,
+		headerHeight:  50 * scale,
+		numbersHeight: 40 * scale,
+	}
+
+	if panels.fleet {
+		l.panelHeight = fleetPanelRows*l.fleetRowHeight() + panelPadding*scale
+	}
+
+	if panels.legend {
+		l.legendHeight = l.fleetRowHeight() + panelPadding*scale
+	}
+
+	if panels.caption {
+		l.captionHeight = captionHeight * scale
+	}
+
+	return l
+}
+
+// boardSize is the size of a board including the grid lines on every side.
+func (l layout) boardSize() image.Point {
+	return image.Pt(10*l.tileWidth+l.line, 10*l.tileHeight+l.line)
+}
+
+// frameSize is the size of everything that is drawn, without the space left over on the canvas.
+func (l layout) frameSize() image.Point {
+	board := l.boardSize()
+
+	return image.Pt(2*(l.labelWidth+board.X), l.headerHeight+l.numbersHeight+board.Y+l.panelsHeight())
+}
+
+// board returns the area of the player board or the enemy board.
+func (l layout) board(side Side) image.Rectangle {
+	left := l.origin.X + l.labelWidth
+	if side == EnemySide {
+		left += l.labelWidth + l.boardSize().X
+	}
+
+	min := image.Pt(left, l.origin.Y+l.headerHeight+l.numbersHeight)
+
+	return image.Rectangle{Min: min, Max: min.Add(l.boardSize())}
+}
+
+// panel returns the area of the fleet status panel.
+func (l layout) panel() image.Rectangle {
+	top := l.board(PlayerSide).Max.Y
+
+	return image.Rect(l.origin.X, top, l.origin.X+l.frameSize().X, top+l.panelHeight)
+}
+
+// legend returns the area of the heatmap legend.
+func (l layout) legend() image.Rectangle {
+	top := l.panel().Max.Y
+
+	return image.Rect(l.origin.X, top, l.origin.X+l.frameSize().X, top+l.legendHeight)
+}
+
+// caption returns the area of the caption banner.
+func (l layout) caption() image.Rectangle {
+	top := l.legend().Max.Y
+
+	return image.Rect(l.origin.X, top, l.origin.X+l.frameSize().X, top+l.captionHeight)
+}
+
+// panelsHeight is the height of everything drawn below the boards.
+func (l layout) panelsHeight() int {
+	return l.panelHeight + l.legendHeight + l.captionHeight
+}
+
+func (l layout) fleetRowHeight() int {
+	return l.tileHeight * 3 / 4
+}
+
+// tile returns the area inside the grid lines of a tile, relative to the board.
+func (l layout) tile(x, y int) image.Rectangle {
+	return image.Rect(
+		x*l.tileWidth+l.line,
+		y*l.tileHeight+l.line,
+		(x+1)*l.tileWidth,
+		(y+1)*l.tileHeight,
+	)
+}
@@ -0,0 +1,276 @@
+package twittership
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrGameNotFound is returned by the Manager when no game exists for an id.
+var ErrGameNotFound = errors.New("game not found")
+
+// ErrGameExists is returned by the Manager when a game already exists for an id.
+var ErrGameExists = errors.New("game already exists")
+
+type managedGame struct {
+	mu            sync.Mutex
+	game          Game
+	progress      int
+	turnStarted   time.Time
+	remindersSent int
+	// moveTimes is when each move of the game was made, moves made before the game was
+	// added use the time it was added
+	moveTimes []time.Time
+	players   Players
+	unrated   bool
+	// recorded is set once the finished game has been claimed to be added to the player
+	// statistics
+	recorded bool
+	// tokens are the secrets each side acts with, empty until IssueTokens is called
+	tokens [2]string
+}
+
+// recordMoveTimes stamps every move that doesn't have a time yet with now.
+func (mg *managedGame) recordMoveTimes(now time.Time) {
+	for len(mg.moveTimes) < len(mg.game.moveOrder) {
+		mg.moveTimes = append(mg.moveTimes, now)
+	}
+}
+
+// Manager owns many games and serialises every operation on a single game while
+// allowing operations on different games to run in parallel.
+type Manager struct {
+	mu     sync.RWMutex
+	games  map[string]*managedGame
+	clock  Clock
+	policy TimeoutPolicy
+	notify func(TimeoutNotice)
+	stats  *Stats
+	// statsErrors is called when a finished game can't be added to the statistics
+	statsErrors func(gameID string, err error)
+	// unrecorded are the finished games waiting to be retried by RetryStats
+	unrecorded []finishedGame
+	// events is called with every event of every game, see setEventHandler
+	events func(id string, e Event)
+}
+
+// NewManager creates an empty game manager.
+func NewManager() *Manager {
+	return &Manager{
+		games: map[string]*managedGame{},
+		clock: systemClock{},
+	}
+}
+
+// Create starts a new game with the id provided.
+func (m *Manager) Create(id string) error {
+	return m.Add(id, NewGame())
+}
+
+// Add hands an existing game over to the manager. The manager takes a private copy
+// of the game so the caller can no longer mutate it outside of the manager.
+func (m *Manager) Add(id string, g Game) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.games[id]; ok {
+		return fmt.Errorf("adding game %s: %w", id, ErrGameExists)
+	}
+
+	mg := &managedGame{
+		game:        g.clone(),
+		progress:    gameProgress(g),
+		turnStarted: m.clock.Now(),
+	}
+	mg.recordMoveTimes(mg.turnStarted)
+	mg.game.Subscribe(func(e Event) {
+		if handler := m.eventHandler(); handler != nil {
+			handler(id, e)
+		}
+	})
+	m.games[id] = mg
+
+	return nil
+}
+
+// setEventHandler sets a function called with every event of every game, however the game
+// was created. It is called while the game is locked so it must not block.
+func (m *Manager) setEventHandler(handler func(id string, e Event)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events = handler
+}
+
+func (m *Manager) eventHandler() func(id string, e Event) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.events
+}
+
+// IssueTokens creates the secret tokens each side of the game with the id provided acts
+// with through the Server, indexed by side. Issuing new tokens revokes the old ones.
+func (m *Manager) IssueTokens(id string) ([2]string, error) {
+	mg, err := m.lookup(id)
+	if err != nil {
+		return [2]string{}, err
+	}
+
+	var tokens [2]string
+	for i := range tokens {
+		tokens[i], err = randomToken(16)
+		if err != nil {
+			return [2]string{}, fmt.Errorf("issuing tokens for game %s: %w", id, err)
+		}
+	}
+
+	mg.mu.Lock()
+	defer mg.mu.Unlock()
+
+	mg.tokens = tokens
+
+	return tokens, nil
+}
+
+// gameTokens returns the tokens issued for the game, which are empty until IssueTokens is
+// called so nobody can act for a side until then.
+func (m *Manager) gameTokens(id string) ([2]string, error) {
+	mg, err := m.lookup(id)
+	if err != nil {
+		return [2]string{}, err
+	}
+
+	mg.mu.Lock()
+	defer mg.mu.Unlock()
+
+	return mg.tokens, nil
+}
+
+// Remove deletes a game from the manager.
+func (m *Manager) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.games[id]; !ok {
+		return fmt.Errorf("removing game %s: %w", id, ErrGameNotFound)
+	}
+
+	delete(m.games, id)
+
+	return nil
+}
+
+// Do runs fn with exclusive access to the game with the id provided. Calls for the
+// same game are serialised, calls for different games may run concurrently. The
+// game pointer must not be retained after fn returns. If fn moves the game forward
+// the turn deadline is reset for the side whose turn it now is. If fn finishes the
+// game it is recorded in the player statistics once the game is unlocked, see
+// SetStatsErrorHandler for how errors doing so are reported.
+func (m *Manager) Do(id string, fn func(g *Game) error) error {
+	mg, err := m.lookup(id)
+	if err != nil {
+		return err
+	}
+
+	mg.mu.Lock()
+
+	err = fn(&mg.game)
+
+	if progress := gameProgress(mg.game); progress != mg.progress {
+		mg.progress = progress
+		mg.turnStarted = m.now()
+		mg.remindersSent = 0
+		mg.recordMoveTimes(mg.turnStarted)
+	}
+
+	finished, record := m.claimStats(id, mg)
+	mg.mu.Unlock()
+
+	if record {
+		m.recordStats(finished)
+	}
+
+	return err
+}
+
+// Get returns a snapshot of the game with the id provided. The snapshot is a deep
+// copy so it is safe to read while other goroutines keep playing the game. The
+// snapshot has no subscribers so changing it never produces events.
+func (m *Manager) Get(id string) (Game, error) {
+	var snapshot Game
+
+	err := m.Do(id, func(g *Game) error {
+		snapshot = g.clone()
+		snapshot.subscribers = nil
+		return nil
+	})
+	if err != nil {
+		return Game{}, err
+	}
+
+	return snapshot, nil
+}
+
+// PlayerVolley executes a single player volley against the game with the id provided.
+func (m *Manager) PlayerVolley(id, position string) (string, error) {
+	var response string
+
+	err := m.Do(id, func(g *Game) error {
+		var err error
+		response, err = g.PlayerVolley(position)
+		return err
+	})
+
+	return response, err
+}
+
+// EnemyVolley executes a single enemy volley against the game with the id provided.
+func (m *Manager) EnemyVolley(id, position string) (string, error) {
+	var response string
+
+	err := m.Do(id, func(g *Game) error {
+		var err error
+		response, err = g.EnemyVolley(position)
+		return err
+	})
+
+	return response, err
+}
+
+// IDs returns the ids of every game in the manager in sorted order.
+func (m *Manager) IDs() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]string, 0, len(m.games))
+	for id := range m.games {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// Len returns the number of games in the manager.
+func (m *Manager) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.games)
+}
+
+func (m *Manager) lookup(id string) (*managedGame, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mg, ok := m.games[id]
+	if !ok {
+		return nil, fmt.Errorf("game %s: %w", id, ErrGameNotFound)
+	}
+
+	return mg, nil
+}
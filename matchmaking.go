@@ -0,0 +1,390 @@
+package twittership
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrAlreadyQueued is returned when a player joins the matchmaking queue twice.
+var ErrAlreadyQueued = errors.New("player is already queued")
+
+// ErrNotQueued is returned when a player who isn't queued leaves the matchmaking queue.
+var ErrNotQueued = errors.New("player is not queued")
+
+// ErrUnsupportedRules is returned when a player asks for a rule variant or board size
+// that games can't be played with.
+var ErrUnsupportedRules = errors.New("unsupported rules")
+
+const (
+	// ClassicVariant is the standard rules with five ships, the only variant games support.
+	ClassicVariant = "classic"
+	// ClassicBoardSize is the width and height of the board, the only size games support.
+	ClassicBoardSize = 10
+)
+
+// MatchRequest is a player asking to be matched with an opponent. Players are only matched
+// with players who asked for the same rules.
+type MatchRequest struct {
+	Handle string `json:"handle"`
+	// Rated games change the ratings of both players.
+	Rated bool `json:"rated"`
+	// Variant is the rules to play with, it defaults to ClassicVariant. Games can only be
+	// played with ClassicVariant for now so Join rejects any other variant, it is part of
+	// the request so clients don't change once more variants are supported.
+	Variant string `json:"variant,omitempty"`
+	// BoardSize is the width and height of the board, it defaults to ClassicBoardSize. Like
+	// Variant, Join rejects any other size until games support them.
+	BoardSize int `json:"boardSize,omitempty"`
+}
+
+// compatible reports whether two requests can be played as the same game.
+func (r MatchRequest) compatible(other MatchRequest) bool {
+	return r.Rated == other.Rated && r.Variant == other.Variant && r.BoardSize == other.BoardSize
+}
+
+// Match is a game created for two players from the matchmaking queue. The player who
+// waited longest plays PlayerSide and fires first.
+type Match struct {
+	GameID    string    `json:"gameId"`
+	Players   Players   `json:"players"`
+	Rated     bool      `json:"rated"`
+	Variant   string    `json:"variant"`
+	BoardSize int       `json:"boardSize"`
+	Created   time.Time `json:"created"`
+}
+
+// MatchNotice tells one player of a match who they are playing.
+type MatchNotice struct {
+	Match    Match
+	Handle   string
+	Side     Side
+	Opponent string
+	// Token is the secret the player acts in the game with through the Server, it must only
+	// be passed on to the player.
+	Token string
+}
+
+// MatchmakerOptions configures how far apart in rating players may be to be matched.
+type MatchmakerOptions struct {
+	// InitialWindow is the largest rating difference accepted as soon as a player joins.
+	// It defaults to 100.
+	InitialWindow float64
+	// WindowGrowth is how much the window widens every GrowthInterval a player waits. It
+	// defaults to 50.
+	WindowGrowth float64
+	// GrowthInterval defaults to 30 seconds.
+	GrowthInterval time.Duration
+	// MaxWindow is the widest the window gets. It defaults to 600, a negative MaxWindow
+	// lets the window grow until any two players are matched.
+	MaxWindow float64
+}
+
+type queueEntry struct {
+	request MatchRequest
+	// rating is read again before every match so games finished while queued count
+	rating float64
+	joined time.Time
+}
+
+// window returns the largest rating difference the player accepts at now.
+func (e queueEntry) window(opts MatchmakerOptions, now time.Time) float64 {
+	steps := math.Floor(float64(now.Sub(e.joined)) / float64(opts.GrowthInterval))
+	window := opts.InitialWindow + steps*opts.WindowGrowth
+
+	if opts.MaxWindow >= 0 && window > opts.MaxWindow {
+		return opts.MaxWindow
+	}
+
+	return window
+}
+
+// Matchmaker pairs queued players with opponents close to their rating and creates a game
+// in the manager for every pair. Each player accepts a wider rating difference the longer
+// they wait.
+type Matchmaker struct {
+	mu      sync.Mutex
+	manager *Manager
+	opts    MatchmakerOptions
+	notify  func(MatchNotice)
+	clock   Clock
+	queue   []queueEntry
+}
+
+// NewMatchmaker creates a matchmaker that creates games in the manager. Ratings are read from
+// the statistics of the manager, players without one use the rating of a new player. The
+// notify function is called for both players of every match, it is never called while the
+// queue is locked so it may safely use the matchmaker.
+func NewMatchmaker(m *Manager, opts MatchmakerOptions, notify func(MatchNotice)) *Matchmaker {
+	if opts.InitialWindow == 0 {
+		opts.InitialWindow = 100
+	}
+
+	if opts.WindowGrowth == 0 {
+		opts.WindowGrowth = 50
+	}
+
+	if opts.GrowthInterval == 0 {
+		opts.GrowthInterval = 30 * time.Second
+	}
+
+	if opts.MaxWindow == 0 {
+		opts.MaxWindow = 600
+	}
+
+	return &Matchmaker{
+		manager: m,
+		opts:    opts,
+		notify:  notify,
+		clock:   systemClock{},
+	}
+}
+
+// SetClock replaces the clock used to time how long players have waited.
+func (mm *Matchmaker) SetClock(clock Clock) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	mm.clock = clock
+}
+
+// Join adds a player to the queue. They are matched the next time Match runs.
+func (mm *Matchmaker) Join(req MatchRequest) error {
+	if req.Variant == "" {
+		req.Variant = ClassicVariant
+	}
+
+	if req.BoardSize == 0 {
+		req.BoardSize = ClassicBoardSize
+	}
+
+	if req.Variant != ClassicVariant || req.BoardSize != ClassicBoardSize {
+		return fmt.Errorf("joining queue: %s on a %dx%d board: %w", req.Variant, req.BoardSize, req.BoardSize, ErrUnsupportedRules)
+	}
+
+	rating, err := mm.manager.playerRating(req.Handle)
+	if err != nil {
+		return fmt.Errorf("joining queue: %w", err)
+	}
+
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	for _, e := range mm.queue {
+		if handleKey(e.request.Handle) == handleKey(req.Handle) {
+			return fmt.Errorf("joining queue %s: %w", req.Handle, ErrAlreadyQueued)
+		}
+	}
+
+	mm.queue = append(mm.queue, queueEntry{request: req, rating: rating, joined: mm.clock.Now()})
+
+	return nil
+}
+
+// Leave removes a player from the queue.
+func (mm *Matchmaker) Leave(handle string) error {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	for i, e := range mm.queue {
+		if handleKey(e.request.Handle) == handleKey(handle) {
+			mm.queue = append(mm.queue[:i], mm.queue[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("leaving queue %s: %w", handle, ErrNotQueued)
+}
+
+// Queued returns the handles of every player waiting for a match, the longest waiting first.
+func (mm *Matchmaker) Queued() []string {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	handles := make([]string, 0, len(mm.queue))
+	for _, e := range mm.queue {
+		handles = append(handles, e.request.Handle)
+	}
+
+	return handles
+}
+
+// Match pairs every player it can and creates their games. Starting with the player who
+// has waited longest, each player is matched with the closest rated compatible player
+// where the difference is within the window of both. Ratings are read again first so
+// players are matched on their current rating. Players whose rating couldn't be read are
+// matched on the last rating read and players whose game couldn't be created stay queued,
+// the first error is returned with the matches that were made.
+func (mm *Matchmaker) Match() ([]Match, error) {
+	mm.mu.Lock()
+	now := mm.clock.Now()
+	firstErr := mm.refreshRatings()
+	pairs := mm.pairs(now)
+
+	var matches []Match
+	var tokens [][2]string
+	matched := map[int]bool{}
+	for _, pair := range pairs {
+		match, matchTokens, err := mm.createGame(mm.queue[pair[0]], mm.queue[pair[1]], now)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		matches = append(matches, match)
+		tokens = append(tokens, matchTokens)
+		matched[pair[0]], matched[pair[1]] = true, true
+	}
+
+	queue := mm.queue[:0]
+	for i, e := range mm.queue {
+		if !matched[i] {
+			queue = append(queue, e)
+		}
+	}
+	mm.queue = queue
+	notify := mm.notify
+	mm.mu.Unlock()
+
+	if notify != nil {
+		for i, match := range matches {
+			for _, side := range []Side{PlayerSide, EnemySide} {
+				notify(MatchNotice{
+					Match:    match,
+					Handle:   match.Players[side],
+					Side:     side,
+					Opponent: match.Players[side.Opponent()],
+					Token:    tokens[i][side],
+				})
+			}
+		}
+	}
+
+	return matches, firstErr
+}
+
+// refreshRatings reads the rating of every queued player again, returning the first error.
+// The queue must be locked.
+func (mm *Matchmaker) refreshRatings() error {
+	var firstErr error
+	for i, e := range mm.queue {
+		rating, err := mm.manager.playerRating(e.request.Handle)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("reading rating of %s: %w", e.request.Handle, err)
+			}
+			continue
+		}
+
+		mm.queue[i].rating = rating
+	}
+
+	return firstErr
+}
+
+// pairs returns the indexes of the queued players to match, the longest waiting first in
+// each pair. The queue must be locked.
+func (mm *Matchmaker) pairs(now time.Time) [][2]int {
+	order := make([]int, len(mm.queue))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return mm.queue[order[i]].joined.Before(mm.queue[order[j]].joined)
+	})
+
+	var pairs [][2]int
+	paired := make([]bool, len(mm.queue))
+	for n, i := range order {
+		if paired[i] {
+			continue
+		}
+
+		a := mm.queue[i]
+		best, bestDiff := -1, math.Inf(1)
+		for _, j := range order[n+1:] {
+			b := mm.queue[j]
+			if paired[j] || !a.request.compatible(b.request) {
+				continue
+			}
+
+			diff := math.Abs(a.rating - b.rating)
+			if diff > a.window(mm.opts, now) || diff > b.window(mm.opts, now) {
+				continue
+			}
+
+			// Players are checked longest waiting first so ties go to the longest waiting
+			if diff < bestDiff {
+				best, bestDiff = j, diff
+			}
+		}
+
+		if best != -1 {
+			paired[i], paired[best] = true, true
+			pairs = append(pairs, [2]int{i, best})
+		}
+	}
+
+	return pairs
+}
+
+// createGame creates the game for a pair of players in the manager.
+func (mm *Matchmaker) createGame(first, second queueEntry, now time.Time) (Match, [2]string, error) {
+	id, err := randomToken(8)
+	if err != nil {
+		return Match{}, [2]string{}, fmt.Errorf("creating match: %w", err)
+	}
+
+	match := Match{
+		GameID:    id,
+		Players:   Players{first.request.Handle, second.request.Handle},
+		Rated:     first.request.Rated,
+		Variant:   first.request.Variant,
+		BoardSize: first.request.BoardSize,
+		Created:   now,
+	}
+
+	err = mm.manager.Create(id)
+	if err != nil {
+		return Match{}, [2]string{}, fmt.Errorf("creating match: %w", err)
+	}
+
+	var tokens [2]string
+	err = mm.manager.SetPlayers(id, match.Players)
+	if err == nil {
+		err = mm.manager.SetRated(id, match.Rated)
+	}
+	if err == nil {
+		tokens, err = mm.manager.IssueTokens(id)
+	}
+	if err != nil {
+		// The players stay queued so the game is removed for the next match to replace it
+		_ = mm.manager.Remove(id)
+		return Match{}, [2]string{}, fmt.Errorf("creating match: %w", err)
+	}
+
+	return match, tokens, nil
+}
+
+// Run matches players every interval until the context is cancelled. Errors are left for
+// the next run to retry as the players stay queued.
+func (mm *Matchmaker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = mm.Match()
+		}
+	}
+}
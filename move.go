@@ -0,0 +1,143 @@
+package twittership
+
+import (
+	"fmt"
+)
+
+// Move is a single volley fired during a game.
+type Move struct {
+	// Number is the position of the move in the game starting at 1.
+	Number   int    `json:"number"`
+	Side     Side   `json:"side"`
+	Position string `json:"position"`
+	X        int    `json:"x"`
+	Y        int    `json:"y"`
+	Hit      bool   `json:"hit"`
+	// Sunk is the name of the ship sunk by this move, if any.
+	Sunk string `json:"sunk,omitempty"`
+}
+
+// Result returns the response given when the move was made I.E. "Hit", "Miss" or
+// "You sunk my Destroyer".
+func (m Move) Result() string {
+	if m.Sunk != "" {
+		return fmt.Sprintf("You sunk my %s", m.Sunk)
+	}
+
+	if m.Hit {
+		return "Hit"
+	}
+
+	return "Miss"
+}
+
+func (g *Game) recordMoves(side Side, count int) {
+	for i := 0; i < count; i++ {
+		g.moveOrder = append(g.moveOrder, side)
+	}
+}
+
+// Moves returns every volley fired in the game by both sides in the order they were fired.
+func (g Game) Moves() []Move {
+	moves := make([]Move, 0, len(g.moveOrder))
+	fired := [2]int{}
+	hits := [2][]int{make([]int, len(g.enemyShips)), make([]int, len(g.playerShips))}
+
+	for i, side := range g.moveOrder {
+		volleys, board, ships := g.playerVolleys, g.enemyBoard, g.enemyShips
+		if side == EnemySide {
+			volleys, board, ships = g.enemyVolleys, g.playerBoard, g.playerShips
+		}
+
+		v := volleys[fired[side]]
+		fired[side]++
+
+		m := Move{
+			Number:   i + 1,
+			Side:     side,
+			Position: positionString(v.x, v.y),
+			X:        v.x,
+			Y:        v.y,
+			Hit:      v.volleyType == hit,
+		}
+
+		if shipIndex := board[v.y][v.x].shipIndex; m.Hit && shipIndex != -1 {
+			hits[side][shipIndex]++
+			if hits[side][shipIndex] == ships[shipIndex].width {
+				m.Sunk = ships[shipIndex].shipType.String()
+			}
+		}
+
+		moves = append(moves, m)
+	}
+
+	return moves
+}
+
+// LastMove returns the most recent move made by either side. The second return value
+// is false if no moves have been made.
+func (g Game) LastMove() (Move, bool) {
+	moves := g.Moves()
+	if len(moves) == 0 {
+		return Move{}, false
+	}
+
+	return moves[len(moves)-1], true
+}
+
+// ShipsPlaced returns true once the side provided has placed their ships.
+func (g Game) ShipsPlaced(side Side) bool {
+	if side == PlayerSide {
+		return len(g.playerShips) > 0
+	}
+
+	return len(g.enemyShips) > 0
+}
+
+// LoadShips loads the ships for the side provided. See LoadPlayerShips for the format.
+func (g *Game) LoadShips(side Side, positions string) error {
+	if side == PlayerSide {
+		return g.LoadPlayerShips(positions)
+	}
+
+	return g.LoadEnemyShips(positions)
+}
+
+// Volley executes a single volley for the side provided. See PlayerVolley and EnemyVolley.
+func (g *Game) Volley(side Side, position string) (string, error) {
+	if side == PlayerSide {
+		return g.PlayerVolley(position)
+	}
+
+	return g.EnemyVolley(position)
+}
+
+// flip returns the game as seen from the enemy side, I.E. the enemy ships and volleys
+// become the player ships and volleys. This lets the enemy side reuse every renderer
+// that draws the game from the player side.
+func (g Game) flip() Game {
+	f := g
+	f.playerShips, f.enemyShips = g.enemyShips, g.playerShips
+	f.playerVolleys, f.enemyVolleys = g.enemyVolleys, g.playerVolleys
+	f.playerBoard, f.enemyBoard = g.enemyBoard, g.playerBoard
+	f.loser = g.loser.Opponent()
+	f.moveOrder = make([]Side, len(g.moveOrder))
+	for i, side := range g.moveOrder {
+		f.moveOrder[i] = side.Opponent()
+	}
+
+	return f
+}
+
+// perspective returns the game as seen by the side provided.
+func (g Game) perspective(side Side) Game {
+	if side == EnemySide {
+		return g.flip()
+	}
+
+	return g
+}
+
+func positionString(x, y int) string {
+	return fmt.Sprintf("%c%d", 'A'+y, x+1)
+}
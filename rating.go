@@ -0,0 +1,358 @@
+package twittership
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNoRatedPlayers is returned when drawing a leaderboard without any players on it.
+var ErrNoRatedPlayers = errors.New("no rated players")
+
+const (
+	defaultRating     = 1500
+	defaultDeviation  = 350
+	defaultVolatility = 0.06
+	// glickoScale converts between the Glicko and Glicko-2 scales.
+	glickoScale = 173.7178
+	// ratingTau limits how quickly the volatility changes.
+	ratingTau = 0.5
+	// volatilityEpsilon is the precision the new volatility is found to.
+	volatilityEpsilon = 0.000001
+	// provisionalDeviation is the deviation above which a rating isn't trusted yet.
+	provisionalDeviation = 110
+)
+
+// Rating is a Glicko-2 rating on the Glicko scale, new players start at 1500 ± 350.
+type Rating struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+// NewRating returns the rating of a player who hasn't played a rated game.
+func NewRating() Rating {
+	return Rating{Rating: defaultRating, Deviation: defaultDeviation, Volatility: defaultVolatility}
+}
+
+// Provisional reports whether the rating is still too uncertain to rank the player.
+func (r Rating) Provisional() bool {
+	return r.Deviation > provisionalDeviation
+}
+
+// String formats the rating rounded to a whole number, provisional ratings end with a ?.
+func (r Rating) String() string {
+	if r.Provisional() {
+		return fmt.Sprintf("%.0f?", r.Rating)
+	}
+
+	return fmt.Sprintf("%.0f", r.Rating)
+}
+
+// RatingResult is the result of a game against an opponent, a Score of 1 is a win and 0 a loss.
+type RatingResult struct {
+	Opponent Rating
+	Score    float64
+}
+
+// Update returns the rating after the games in results, which are treated as a single
+// rating period. Without any results only the deviation grows.
+func (r Rating) Update(results []RatingResult) Rating {
+	mu := (r.Rating - defaultRating) / glickoScale
+	phi := r.Deviation / glickoScale
+
+	if len(results) == 0 {
+		phi = math.Sqrt(phi*phi + r.Volatility*r.Volatility)
+		return Rating{Rating: r.Rating, Deviation: phi * glickoScale, Volatility: r.Volatility}
+	}
+
+	variance, improvement := 0.0, 0.0
+	for _, result := range results {
+		muj := (result.Opponent.Rating - defaultRating) / glickoScale
+		g := glickoG(result.Opponent.Deviation / glickoScale)
+		e := 1 / (1 + math.Exp(-g*(mu-muj)))
+
+		variance += g * g * e * (1 - e)
+		improvement += g * (result.Score - e)
+	}
+	variance = 1 / variance
+	delta := variance * improvement
+
+	sigma := newVolatility(phi, r.Volatility, variance, delta)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/variance)
+	mu += phi * phi * improvement
+
+	return Rating{
+		Rating:     mu*glickoScale + defaultRating,
+		Deviation:  phi * glickoScale,
+		Volatility: sigma,
+	}
+}
+
+func glickoG(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// newVolatility finds the volatility after a rating period with the Illinois algorithm
+// described in step 5 of the Glicko-2 paper.
+func newVolatility(phi, sigma, variance, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + variance + ex
+
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(ratingTau*ratingTau)
+	}
+
+	lower := a
+	var upper float64
+	if delta*delta > phi*phi+variance {
+		upper = math.Log(delta*delta - phi*phi - variance)
+	} else {
+		k := 1.0
+		for f(a-k*ratingTau) < 0 {
+			k++
+		}
+		upper = a - k*ratingTau
+	}
+
+	fLower, fUpper := f(lower), f(upper)
+	for math.Abs(upper-lower) > volatilityEpsilon {
+		c := lower + (lower-upper)*fLower/(fUpper-fLower)
+		fc := f(c)
+
+		if fc*fUpper <= 0 {
+			lower, fLower = upper, fUpper
+		} else {
+			fLower /= 2
+		}
+
+		upper, fUpper = c, fc
+	}
+
+	return math.Exp(lower / 2)
+}
+
+// RatingChange is the rating of a player after a rated game.
+type RatingChange struct {
+	Time     time.Time `json:"time"`
+	Opponent string    `json:"opponent"`
+	Score    float64   `json:"score"`
+	Rating   Rating    `json:"rating"`
+}
+
+// ratingAt returns the rating the player had at t according to their history.
+func (s PlayerStats) ratingAt(t time.Time) Rating {
+	rating := NewRating()
+	for _, change := range s.RatingHistory {
+		if change.Time.After(t) {
+			break
+		}
+
+		rating = change.Rating
+	}
+
+	return rating
+}
+
+// rateGame updates the ratings of both players after a finished game.
+func rateGame(players [2]*PlayerStats, g Game, now time.Time) {
+	winner, _ := g.Winner()
+
+	var ratings [2]Rating
+	for _, side := range []Side{PlayerSide, EnemySide} {
+		score := 0.0
+		if winner == side {
+			score = 1
+		}
+
+		opponent := players[side.Opponent()]
+		ratings[side] = players[side].Rating.Update([]RatingResult{{Opponent: opponent.Rating, Score: score}})
+		players[side].RatingHistory = append(players[side].RatingHistory, RatingChange{
+			Time:     now,
+			Opponent: opponent.Handle,
+			Score:    score,
+			Rating:   ratings[side],
+		})
+	}
+
+	for _, side := range []Side{PlayerSide, EnemySide} {
+		players[side].Rating = ratings[side]
+	}
+}
+
+// LeaderboardOptions configures which players are on a leaderboard.
+type LeaderboardOptions struct {
+	// Limit is the most players on the leaderboard, zero includes every player.
+	Limit int
+	// IncludeProvisional adds players whose rating is still provisional.
+	IncludeProvisional bool
+	// Since is when the rating change of each player is measured from, I.E. a week ago for
+	// a weekly leaderboard. The zero value leaves every change at zero.
+	Since time.Time
+}
+
+// LeaderboardEntry is the position of a player on a leaderboard.
+type LeaderboardEntry struct {
+	Rank   int    `json:"rank"`
+	Handle string `json:"handle"`
+	Rating Rating `json:"rating"`
+	Games  int    `json:"games"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	// Change is how much the rating moved since LeaderboardOptions.Since.
+	Change float64 `json:"change"`
+}
+
+// Leaderboard returns the players with a rating, the highest rating first.
+func (s *Stats) Leaderboard(opts LeaderboardOptions) ([]LeaderboardEntry, error) {
+	players, err := s.store.AllPlayers()
+	if err != nil {
+		return nil, fmt.Errorf("loading leaderboard: %w", err)
+	}
+
+	entries := []LeaderboardEntry{}
+	for _, player := range players {
+		if len(player.RatingHistory) == 0 || (player.Rating.Provisional() && !opts.IncludeProvisional) {
+			continue
+		}
+
+		entry := LeaderboardEntry{
+			Handle: player.Handle,
+			Rating: player.Rating,
+			Games:  player.Games,
+			Wins:   player.Wins,
+			Losses: player.Losses,
+		}
+
+		if !opts.Since.IsZero() {
+			entry.Change = player.Rating.Rating - player.ratingAt(opts.Since).Rating
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Rating.Rating != entries[j].Rating.Rating {
+			return entries[i].Rating.Rating > entries[j].Rating.Rating
+		}
+
+		return handleKey(entries[i].Handle) < handleKey(entries[j].Handle)
+	})
+
+	if opts.Limit > 0 && len(entries) > opts.Limit {
+		entries = entries[:opts.Limit]
+	}
+
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+
+	return entries, nil
+}
+
+// leaderboardRow formats the columns of a leaderboard entry.
+func leaderboardRow(e LeaderboardEntry) []string {
+	change := ""
+	if math.Round(e.Change) != 0 {
+		change = fmt.Sprintf("%+.0f", e.Change)
+	}
+
+	return []string{
+		fmt.Sprintf("%d", e.Rank),
+		e.Handle,
+		e.Rating.String(),
+		fmt.Sprintf("%d-%d", e.Wins, e.Losses),
+		change,
+	}
+}
+
+var leaderboardHeader = []string{"#", "Player", "Rating", "W-L", "+/-"}
+
+// GetLeaderboardText returns the leaderboard as a table of text. Provisional ratings end
+// with a ?.
+func GetLeaderboardText(entries []LeaderboardEntry) string {
+	rows := [][]string{leaderboardHeader}
+	for _, e := range entries {
+		rows = append(rows, leaderboardRow(e))
+	}
+
+	return textTable(rows, []bool{true, false, true, true, true})
+}
+
+// LeaderboardImageOptions configures how NewLeaderboardImage draws a leaderboard.
+type LeaderboardImageOptions struct {
+	// Theme selects the colors. The zero value uses ClassicTheme.
+	Theme Theme
+	// Title is drawn above the table. It defaults to "Leaderboard".
+	Title string
+	// Width is the width of the image. It defaults to 600.
+	Width int
+}
+
+// leaderboardColumns are the fractions of the width taken by each column.
+var leaderboardColumns = []float64{0.1, 0.42, 0.18, 0.15, 0.15}
+
+// LeaderboardImage is a leaderboard drawn as a table, for posting with the weekly rankings.
+type LeaderboardImage struct {
+	fullImage *image.RGBA
+}
+
+// NewLeaderboardImage draws the leaderboard as a table below a title. It returns
+// ErrNoRatedPlayers when there are no entries.
+func NewLeaderboardImage(entries []LeaderboardEntry, opts LeaderboardImageOptions) (LeaderboardImage, error) {
+	if len(entries) == 0 {
+		return LeaderboardImage{}, ErrNoRatedPlayers
+	}
+
+	if opts.Theme.Name == "" {
+		opts.Theme = ClassicTheme
+	}
+
+	if opts.Title == "" {
+		opts.Title = "Leaderboard"
+	}
+
+	if opts.Width == 0 {
+		opts.Width = 600
+	}
+
+	rows := [][]string{leaderboardHeader}
+	for _, e := range entries {
+		rows = append(rows, leaderboardRow(e))
+	}
+
+	img := newTitledImage(opts.Width, tableTitleHeight+len(rows)*tableRowHeight, opts.Title, opts.Theme)
+	drawTable(img, image.Rect(0, tableTitleHeight, opts.Width, img.Rect.Max.Y), rows, leaderboardColumns, opts.Theme, func(row, column int, cell string) color.RGBA {
+		if row > 0 && column == 4 && strings.HasPrefix(cell, "-") {
+			return opts.Theme.Hit
+		}
+
+		return opts.Theme.Label
+	})
+
+	return LeaderboardImage{fullImage: img}, nil
+}
+
+// Encode writes the leaderboard image to w in the format provided.
+func (li LeaderboardImage) Encode(w io.Writer, format ImageFormat) error {
+	err := encodeImage(w, li.fullImage, format)
+	if err != nil {
+		return fmt.Errorf("unable to encode leaderboard image: %w", err)
+	}
+
+	return nil
+}
+
+// GetFullImage returns the image.RGBA fullImage.
+func (li LeaderboardImage) GetFullImage() *image.RGBA {
+	return li.fullImage
+}
@@ -0,0 +1,342 @@
+package twittership
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxReplayGIFBytes is the largest animated GIF Twitter accepts.
+const MaxReplayGIFBytes = 15 << 20
+
+var (
+	// ErrNoMoves is returned when replaying a game that has no moves.
+	ErrNoMoves = errors.New("the game has no moves to replay")
+	// ErrReplayTooLarge is returned when a replay GIF is larger than ReplayOptions.MaxBytes.
+	ErrReplayTooLarge = errors.New("replay gif is too large")
+)
+
+// ReplayOptions configures how RenderReplayGIF draws a replay.
+type ReplayOptions struct {
+	// Image configures how every frame is drawn.
+	Image ImageOptions
+	// Height and Width are the size of each board. They default to 401.
+	Height int
+	Width  int
+	// Side is the side the game is replayed from, its ships are shown and the opponent
+	// ships are only revealed once sunk.
+	Side Side
+	// Captions adds a caption below the boards describing the newest shot I.E.
+	// "Player fired at B7 - Hit!".
+	Captions bool
+	// Delay is how long each frame is shown. It defaults to one second.
+	Delay time.Duration
+	// FinalDelay is how long the last frame is shown before the replay loops. It defaults
+	// to three seconds.
+	FinalDelay time.Duration
+	// MaxBytes is the largest GIF that may be written. It defaults to MaxReplayGIFBytes.
+	MaxBytes int
+}
+
+// RenderReplayGIF writes an animated GIF to w with one frame for every shot of the game.
+// The newest shot is highlighted on every frame. Each frame only contains the pixels that
+// changed since the previous frame and every frame shares one palette so replays of whole
+// games stay well under the size limit. Frames are converted as they are drawn so only
+// the last frame is ever held in full.
+func RenderReplayGIF(g Game, opts ReplayOptions, w io.Writer) error {
+	opts = opts.withDefaults()
+
+	moves := g.Moves()
+	if len(moves) == 0 {
+		return ErrNoMoves
+	}
+
+	// The first and last frames hold every color a replay draws between them, so with the
+	// palette chosen up front each frame is palettized as soon as it is drawn and only the
+	// part that changed is kept
+	last, err := replayLastFrame(g, moves, opts)
+	if err != nil {
+		return err
+	}
+
+	replay, err := replayStart(g)
+	if err != nil {
+		return fmt.Errorf("unable to replay game: %w", err)
+	}
+
+	var enc *replayEncoder
+	for i, m := range moves {
+		frame := last
+		if i < len(moves)-1 {
+			frame, err = replayNextFrame(&replay, m, opts)
+			if err != nil {
+				return err
+			}
+		}
+
+		if enc == nil {
+			enc = newReplayEncoder(framePalette(frame, last), frame.Bounds(), opts)
+		}
+
+		enc.add(frame, i == len(moves)-1)
+	}
+
+	var buf bytes.Buffer
+	err = gif.EncodeAll(&buf, enc.anim)
+	if err != nil {
+		return fmt.Errorf("unable to encode replay gif: %w", err)
+	}
+
+	if buf.Len() > opts.MaxBytes {
+		return fmt.Errorf("%w: %d bytes is over the limit of %d bytes", ErrReplayTooLarge, buf.Len(), opts.MaxBytes)
+	}
+
+	_, err = buf.WriteTo(w)
+	if err != nil {
+		return fmt.Errorf("unable to write replay gif: %w", err)
+	}
+
+	return nil
+}
+
+func (opts ReplayOptions) withDefaults() ReplayOptions {
+	if opts.Height == 0 {
+		opts.Height = 401
+	}
+
+	if opts.Width == 0 {
+		opts.Width = 401
+	}
+
+	if opts.Delay == 0 {
+		opts.Delay = time.Second
+	}
+
+	if opts.FinalDelay == 0 {
+		opts.FinalDelay = 3 * time.Second
+	}
+
+	if opts.MaxBytes == 0 {
+		opts.MaxBytes = MaxReplayGIFBytes
+	}
+
+	if opts.Image.Theme.Name == "" {
+		opts.Image.Theme = ClassicTheme
+	}
+
+	return opts
+}
+
+// replayStart returns a new game with the same ships as g and no volleys.
+func replayStart(g Game) (Game, error) {
+	replay := NewGame()
+
+	for _, side := range []Side{PlayerSide, EnemySide} {
+		ships := g.playerShips
+		if side == EnemySide {
+			ships = g.enemyShips
+		}
+
+		positions := make([]string, 0, len(ships))
+		for _, s := range ships {
+			positions = append(positions, shipPositionString(s))
+		}
+
+		err := replay.LoadShips(side, strings.Join(positions, ";"))
+		if err != nil {
+			return Game{}, err
+		}
+	}
+
+	return replay, nil
+}
+
+// perspective returns the move as seen by the side provided.
+func (m Move) perspective(side Side) Move {
+	if side == EnemySide {
+		m.Side = m.Side.Opponent()
+	}
+
+	return m
+}
+
+// replayFrame draws a single frame of a replay with the newest move highlighted.
+func replayFrame(g Game, m Move, opts ReplayOptions) (*image.RGBA, error) {
+	imageOpts := opts.Image
+	imageOpts.Caption = imageOpts.Caption || opts.Captions
+
+	gi, err := NewGameImageWithOptions(g, opts.Height, opts.Width, imageOpts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to draw replay frame %d: %w", m.Number, err)
+	}
+
+	gi.highlightMove(m)
+
+	return gi.fullImage, nil
+}
+
+// replayNextFrame fires the move in the replay and draws the frame showing it.
+func replayNextFrame(replay *Game, m Move, opts ReplayOptions) (*image.RGBA, error) {
+	_, err := replay.Volley(m.Side, m.Position)
+	if err != nil {
+		return nil, fmt.Errorf("unable to replay move %d: %w", m.Number, err)
+	}
+
+	return replayFrame(replay.perspective(opts.Side), m.perspective(opts.Side), opts)
+}
+
+// replayLastFrame draws the frame showing the last move of the game.
+func replayLastFrame(g Game, moves []Move, opts ReplayOptions) (*image.RGBA, error) {
+	replay, err := replayStart(g)
+	if err != nil {
+		return nil, fmt.Errorf("unable to replay game: %w", err)
+	}
+
+	for _, m := range moves[:len(moves)-1] {
+		_, err = replay.Volley(m.Side, m.Position)
+		if err != nil {
+			return nil, fmt.Errorf("unable to replay move %d: %w", m.Number, err)
+		}
+	}
+
+	return replayNextFrame(&replay, moves[len(moves)-1], opts)
+}
+
+// replayEncoder converts frames to paletted images sharing one palette as they are drawn.
+// After the first frame only the rectangle that changed is stored and unchanged pixels
+// within it are transparent so the previous frame shows through.
+type replayEncoder struct {
+	anim        *gif.GIF
+	pal         color.Palette
+	transparent uint8
+	indexes     map[color.RGBA]uint8
+	// previous is the last frame added in full, the next frame is compared with it
+	previous *image.Paletted
+	opts     ReplayOptions
+}
+
+func newReplayEncoder(pal color.Palette, bounds image.Rectangle, opts ReplayOptions) *replayEncoder {
+	transparent := uint8(len(pal))
+	pal = append(pal, color.RGBA{})
+
+	return &replayEncoder{
+		anim: &gif.GIF{
+			Config: image.Config{ColorModel: pal, Width: bounds.Dx(), Height: bounds.Dy()},
+		},
+		pal:         pal,
+		transparent: transparent,
+		indexes:     map[color.RGBA]uint8{},
+		opts:        opts,
+	}
+}
+
+// add appends a frame to the replay, the final frame is shown for FinalDelay.
+func (e *replayEncoder) add(frame *image.RGBA, final bool) {
+	bounds := frame.Bounds()
+	full := image.NewPaletted(bounds, e.pal)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := frame.RGBAAt(x, y)
+			index, ok := e.indexes[c]
+			if !ok {
+				index = uint8(e.pal[:e.transparent].Index(c))
+				e.indexes[c] = index
+			}
+
+			full.SetColorIndex(x, y, index)
+		}
+	}
+
+	img := full
+	if e.previous != nil {
+		img = paletteDelta(e.previous, full, e.transparent)
+	}
+
+	delay := e.opts.Delay
+	if final {
+		delay = e.opts.FinalDelay
+	}
+
+	e.anim.Image = append(e.anim.Image, img)
+	e.anim.Delay = append(e.anim.Delay, int(delay/(10*time.Millisecond)))
+	e.anim.Disposal = append(e.anim.Disposal, gif.DisposalNone)
+	e.previous = full
+}
+
+// paletteDelta returns the smallest rectangle of current that differs from previous with
+// every unchanged pixel set to the transparent index.
+func paletteDelta(previous, current *image.Paletted, transparent uint8) *image.Paletted {
+	changed := image.Rectangle{}
+	for y := current.Rect.Min.Y; y < current.Rect.Max.Y; y++ {
+		for x := current.Rect.Min.X; x < current.Rect.Max.X; x++ {
+			if previous.ColorIndexAt(x, y) != current.ColorIndexAt(x, y) {
+				changed = changed.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+
+	// A frame must contain at least one pixel
+	if changed.Empty() {
+		changed = image.Rect(0, 0, 1, 1)
+	}
+
+	delta := image.NewPaletted(changed, current.Palette)
+	for y := changed.Min.Y; y < changed.Max.Y; y++ {
+		for x := changed.Min.X; x < changed.Max.X; x++ {
+			index := current.ColorIndexAt(x, y)
+			if index == previous.ColorIndexAt(x, y) {
+				index = transparent
+			}
+
+			delta.SetColorIndex(x, y, index)
+		}
+	}
+
+	return delta
+}
+
+// framePalette chooses up to 255 of the most common colors in the frames, leaving room for
+// a transparent color. Game images are drawn from a small set of theme colors so these
+// cover nearly every pixel, any other color uses the closest one.
+func framePalette(frames ...*image.RGBA) color.Palette {
+	counts := map[color.RGBA]int{}
+	for _, frame := range frames {
+		for y := frame.Rect.Min.Y; y < frame.Rect.Max.Y; y++ {
+			for x := frame.Rect.Min.X; x < frame.Rect.Max.X; x++ {
+				counts[frame.RGBAAt(x, y)]++
+			}
+		}
+	}
+
+	colors := make([]color.RGBA, 0, len(counts))
+	for c := range counts {
+		colors = append(colors, c)
+	}
+
+	sort.Slice(colors, func(i, j int) bool {
+		if counts[colors[i]] != counts[colors[j]] {
+			return counts[colors[i]] > counts[colors[j]]
+		}
+
+		a, b := colors[i], colors[j]
+		return uint32(a.R)<<24|uint32(a.G)<<16|uint32(a.B)<<8|uint32(a.A) < uint32(b.R)<<24|uint32(b.G)<<16|uint32(b.B)<<8|uint32(b.A)
+	})
+
+	if len(colors) > 255 {
+		colors = colors[:255]
+	}
+
+	pal := make(color.Palette, 0, len(colors))
+	for _, c := range colors {
+		pal = append(pal, c)
+	}
+
+	return pal
+}
@@ -0,0 +1,691 @@
+package twittership
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	errUnauthorized         = errors.New("missing or invalid token")
+	errShipsAlreadyPlaced   = errors.New("ships have already been placed")
+	errShipsNotPlaced       = errors.New("both sides must place their ships first")
+	errNotYourTurn          = errors.New("it is not your turn")
+	errGameFinished         = errors.New("game is finished")
+	errUnknownFormat        = errors.New("format must be png, jpeg or gif")
+	errInvalidPosition      = errors.New("position must be a single tile from A1 to J10")
+	errInvalidShipPositions = errors.New("positions must be five ships separated by ; such as A1H;B8V;E3H;G3V;H8H")
+)
+
+// Server exposes the games held by a Manager as an HTTP JSON API. Every game has a
+// secret token for each side and every request must carry the token of the side it
+// acts for in an "Authorization: Bearer <token>" header, so one side can never see
+// or act for the other. Spectators need no token, the spectator routes only show
+// what has been revealed on both boards.
+//
+//	POST /games                   create a game, returns the id and both tokens. Nothing
+//	                              ties the caller to a player so these games are never
+//	                              recorded in the statistics, only games created by a
+//	                              Matchmaker or Tournament are
+//	GET  /games/{id}              redacted state for the side of the token
+//	POST /games/{id}/ships        place ships, body {"positions": "A1H;B8V;E3H;G3V;H8H"}
+//	POST /games/{id}/volleys      fire a volley, body {"position": "B7"}
+//	GET  /games/{id}/moves        every move made so far
+//	GET  /games/{id}/board.png    board image for the side of the token, ?format=jpeg or
+//	                              ?format=gif encode it as a JPEG or GIF instead
+//	GET  /games/{id}/board.txt    board text for the side of the token
+//	GET  /games/{id}/ws           websocket of live events, see serveLive
+//	GET  /games/{id}/spectate     state of both boards for spectators
+//	GET  /games/{id}/spectate.png board image for spectators
+//	GET  /feed                    most recent moves across every live game, ?limit=n
+//	GET  /feed.png                digest image of the live games
+//	GET  /players/{handle}        statistics of the player with the handle
+//	GET  /leaderboard             players ranked by rating, ?limit=n, ?days=n measures the
+//	                              rating change over the last n days and ?provisional=true
+//	                              includes provisional ratings
+//	GET  /leaderboard.png         leaderboard image, with the same parameters
+//	GET  /leaderboard.txt         leaderboard text, with the same parameters
+type Server struct {
+	manager  *Manager
+	template string
+	mu       sync.RWMutex
+	hubs     map[string]*liveHub
+}
+
+// NewServer creates a server for the games in the manager. The template is the path
+// to the board template used when rendering board images, an empty path uses the
+// embedded template. Clients may act in any game of the manager that tokens have been
+// issued for, and watch every game of the manager however it was created.
+func NewServer(m *Manager, template string) *Server {
+	s := &Server{
+		manager:  m,
+		template: template,
+		hubs:     map[string]*liveHub{},
+	}
+
+	m.setEventHandler(func(id string, e Event) {
+		if event, ok := liveEventFor(e); ok {
+			s.publish(id, event)
+		}
+	})
+
+	return s
+}
+
+type createGameResponse struct {
+	ID          string `json:"id"`
+	PlayerToken string `json:"playerToken"`
+	EnemyToken  string `json:"enemyToken"`
+}
+
+type playerStatsResponse struct {
+	PlayerStats
+	Accuracy          float64  `json:"accuracy"`
+	AverageShotsToWin float64  `json:"averageShotsToWin"`
+	FavouriteOpenings []string `json:"favouriteOpenings"`
+}
+
+type placeShipsRequest struct {
+	Positions string `json:"positions"`
+}
+
+type volleyRequest struct {
+	Position string `json:"position"`
+}
+
+type volleyResponse struct {
+	Result   string `json:"result"`
+	Move     Move   `json:"move"`
+	Finished bool   `json:"finished"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) == 1 {
+		switch parts[0] {
+		case "feed":
+			s.route(w, r, http.MethodGet, s.getFeed)
+			return
+		case "feed.png":
+			s.route(w, r, http.MethodGet, s.getFeedImage)
+			return
+		case "leaderboard", "leaderboard.png", "leaderboard.txt":
+			s.route(w, r, http.MethodGet, s.getLeaderboard)
+			return
+		}
+	}
+
+	if parts[0] == "players" && len(parts) == 2 {
+		s.route(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+			s.getPlayerStats(w, r, parts[1])
+		})
+		return
+	}
+
+	if parts[0] != "games" || len(parts) > 3 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown path: %s", r.URL.Path))
+		return
+	}
+
+	if len(parts) == 1 {
+		s.route(w, r, http.MethodPost, s.createGame)
+		return
+	}
+
+	id := parts[1]
+	if len(parts) == 2 {
+		s.route(w, r, http.MethodGet, s.authenticated(id, s.getState))
+		return
+	}
+
+	switch parts[2] {
+	case "ships":
+		s.route(w, r, http.MethodPost, s.authenticated(id, s.placeShips))
+	case "volleys":
+		s.route(w, r, http.MethodPost, s.authenticated(id, s.fireVolley))
+	case "moves":
+		s.route(w, r, http.MethodGet, s.authenticated(id, s.listMoves))
+	case "board.png":
+		s.route(w, r, http.MethodGet, s.authenticated(id, s.getBoardImage))
+	case "board.txt":
+		s.route(w, r, http.MethodGet, s.authenticated(id, s.getBoardText))
+	case "spectate":
+		s.route(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+			s.getSpectatorState(w, r, id)
+		})
+	case "spectate.png":
+		s.route(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+			s.getSpectatorImage(w, r, id)
+		})
+	case "ws":
+		s.route(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+			s.serveLive(w, r, id)
+		})
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown path: %s", r.URL.Path))
+	}
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request, method string, handler http.HandlerFunc) {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	handler(w, r)
+}
+
+type sideHandler func(w http.ResponseWriter, r *http.Request, id string, side Side)
+
+func (s *Server) authenticated(id string, handler sideHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		side, err := s.authenticate(r, id)
+		if err != nil {
+			writeManagerError(w, err)
+			return
+		}
+
+		handler(w, r, id, side)
+	}
+}
+
+// authenticate returns the side that the request token belongs to.
+func (s *Server) authenticate(r *http.Request, id string) (Side, error) {
+	tokens, err := s.manager.gameTokens(id)
+	if err != nil {
+		return PlayerSide, err
+	}
+
+	return sideForToken(tokens, bearerToken(r))
+}
+
+func sideForToken(tokens [2]string, token string) (Side, error) {
+	if token == "" {
+		return PlayerSide, errUnauthorized
+	}
+
+	for _, side := range []Side{PlayerSide, EnemySide} {
+		if subtle.ConstantTimeCompare([]byte(tokens[side]), []byte(token)) == 1 {
+			return side, nil
+		}
+	}
+
+	return PlayerSide, errUnauthorized
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ""
+	}
+
+	return strings.TrimPrefix(header, "Bearer ")
+}
+
+func (s *Server) createGame(w http.ResponseWriter, r *http.Request) {
+	id, err := randomToken(8)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = s.manager.Create(id)
+	if err != nil {
+		writeManagerError(w, err)
+		return
+	}
+
+	tokens, err := s.manager.IssueTokens(id)
+	if err != nil {
+		_ = s.manager.Remove(id)
+		writeManagerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, createGameResponse{
+		ID:          id,
+		PlayerToken: tokens[PlayerSide],
+		EnemyToken:  tokens[EnemySide],
+	})
+}
+
+func (s *Server) getState(w http.ResponseWriter, r *http.Request, id string, side Side) {
+	g, err := s.manager.Get(id)
+	if err != nil {
+		writeManagerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, g.View(side))
+}
+
+func (s *Server) placeShips(w http.ResponseWriter, r *http.Request, id string, side Side) {
+	var req placeShipsRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("decoding request: %w", err))
+		return
+	}
+
+	var view PlayerView
+	err = s.manager.Do(id, func(g *Game) error {
+		err := placeShipsForSide(g, side, req.Positions)
+		if err != nil {
+			return err
+		}
+
+		view = g.View(side)
+		return nil
+	})
+	if err != nil {
+		writeManagerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, view)
+}
+
+func (s *Server) fireVolley(w http.ResponseWriter, r *http.Request, id string, side Side) {
+	var req volleyRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("decoding request: %w", err))
+		return
+	}
+
+	var res volleyResponse
+	err = s.manager.Do(id, func(g *Game) error {
+		var err error
+		res, err = volleyForSide(g, side, req.Position)
+		return err
+	})
+	if err != nil {
+		writeManagerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// shipPositionPattern matches the tile and direction of a single ship.
+var shipPositionPattern = regexp.MustCompile(`^[A-J](10|[1-9])[HV]$`)
+
+// placeShipsForSide places the ships for side after checking that it is allowed to and that
+// there is exactly one valid position for each of the five ships.
+func placeShipsForSide(g *Game, side Side, positions string) error {
+	ships := strings.Split(positions, ";")
+	if len(ships) != 5 {
+		return badRequest{fmt.Errorf("%q: %w", positions, errInvalidShipPositions)}
+	}
+
+	for _, ship := range ships {
+		if !shipPositionPattern.MatchString(ship) {
+			return badRequest{fmt.Errorf("%q: %w", ship, errInvalidShipPositions)}
+		}
+	}
+
+	if g.IsFinished() {
+		return errGameFinished
+	}
+
+	if g.ShipsPlaced(side) {
+		return errShipsAlreadyPlaced
+	}
+
+	err := g.LoadShips(side, positions)
+	if err != nil {
+		return badRequest{err}
+	}
+
+	return nil
+}
+
+// volleyPositionPattern matches a single tile. Games accept several positions separated by
+// ";" so clients must be limited to one shot per turn before reaching the game.
+var volleyPositionPattern = regexp.MustCompile(`^[A-J](10|[1-9])$`)
+
+// volleyForSide fires a volley for side after checking that it is allowed to.
+func volleyForSide(g *Game, side Side, position string) (volleyResponse, error) {
+	if !volleyPositionPattern.MatchString(position) {
+		return volleyResponse{}, badRequest{fmt.Errorf("%q: %w", position, errInvalidPosition)}
+	}
+
+	if g.IsFinished() {
+		return volleyResponse{}, errGameFinished
+	}
+
+	if !g.ShipsPlaced(PlayerSide) || !g.ShipsPlaced(EnemySide) {
+		return volleyResponse{}, errShipsNotPlaced
+	}
+
+	if g.Turn() != side {
+		return volleyResponse{}, errNotYourTurn
+	}
+
+	result, err := g.Volley(side, position)
+	if err != nil {
+		return volleyResponse{}, badRequest{err}
+	}
+
+	move, _ := g.LastMove()
+
+	return volleyResponse{
+		Result:   result,
+		Move:     move,
+		Finished: g.IsFinished(),
+	}, nil
+}
+
+func (s *Server) listMoves(w http.ResponseWriter, r *http.Request, id string, side Side) {
+	g, err := s.manager.Get(id)
+	if err != nil {
+		writeManagerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, g.Moves())
+}
+
+func (s *Server) getBoardImage(w http.ResponseWriter, r *http.Request, id string, side Side) {
+	width, err := queryInt(r, "width", 401)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	height, err := queryInt(r, "height", 401)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	format, err := queryFormat(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	g, err := s.manager.Get(id)
+	if err != nil {
+		writeManagerError(w, err)
+		return
+	}
+
+	gi, err := NewGameImageFromGame(g.perspective(side), height, width, s.template)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	err = gi.Encode(w, format)
+	if err != nil {
+		// The header has already been written so the error can't be reported to the client
+		return
+	}
+}
+
+func (s *Server) getSpectatorState(w http.ResponseWriter, r *http.Request, id string) {
+	g, err := s.manager.Get(id)
+	if err != nil {
+		writeManagerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, g.SpectatorView())
+}
+
+func (s *Server) getSpectatorImage(w http.ResponseWriter, r *http.Request, id string) {
+	width, err := queryInt(r, "width", 401)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	height, err := queryInt(r, "height", 401)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	format, err := queryFormat(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	g, err := s.manager.Get(id)
+	if err != nil {
+		writeManagerError(w, err)
+		return
+	}
+
+	gi, err := NewGameImageWithOptions(g, height, width, ImageOptions{Spectator: true, HighlightLastMoves: true, Caption: true})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	_ = gi.Encode(w, format)
+}
+
+func (s *Server) getFeed(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > 100 {
+			writeError(w, http.StatusBadRequest, errors.New("limit must be a number between 1 and 100"))
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, s.manager.Feed(limit))
+}
+
+func (s *Server) getFeedImage(w http.ResponseWriter, r *http.Request) {
+	format, err := queryFormat(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	di, err := NewLiveDigestImage(s.manager, DigestOptions{})
+	if errors.Is(err, ErrNoLiveGames) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	_ = di.Encode(w, format)
+}
+
+func (s *Server) getPlayerStats(w http.ResponseWriter, r *http.Request, handle string) {
+	stats := s.manager.statsRecorder()
+	if stats == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("player %s: %w", handle, ErrPlayerNotFound))
+		return
+	}
+
+	player, err := stats.Player(handle)
+	if err != nil {
+		writeManagerError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, playerStatsResponse{
+		PlayerStats:       player,
+		Accuracy:          player.Accuracy(),
+		AverageShotsToWin: player.AverageShotsToWin(),
+		FavouriteOpenings: player.FavouriteOpenings(3),
+	})
+}
+
+func (s *Server) getLeaderboard(w http.ResponseWriter, r *http.Request) {
+	opts := LeaderboardOptions{Limit: 10, IncludeProvisional: r.URL.Query().Get("provisional") == "true"}
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		opts.Limit, err = strconv.Atoi(value)
+		if err != nil || opts.Limit < 1 || opts.Limit > 100 {
+			writeError(w, http.StatusBadRequest, errors.New("limit must be a number between 1 and 100"))
+			return
+		}
+	}
+
+	if value := r.URL.Query().Get("days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 || days > 365 {
+			writeError(w, http.StatusBadRequest, errors.New("days must be a number between 1 and 365"))
+			return
+		}
+
+		opts.Since = s.manager.now().AddDate(0, 0, -days)
+	}
+
+	format, err := queryFormat(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	entries := []LeaderboardEntry{}
+	if stats := s.manager.statsRecorder(); stats != nil {
+		entries, err = stats.Leaderboard(opts)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, ".txt"):
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(GetLeaderboardText(entries)))
+	case strings.HasSuffix(r.URL.Path, ".png"):
+		li, err := NewLeaderboardImage(entries, LeaderboardImageOptions{})
+		if errors.Is(err, ErrNoRatedPlayers) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		_ = li.Encode(w, format)
+	default:
+		writeJSON(w, http.StatusOK, entries)
+	}
+}
+
+func (s *Server) getBoardText(w http.ResponseWriter, r *http.Request, id string, side Side) {
+	g, err := s.manager.Get(id)
+	if err != nil {
+		writeManagerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	g = g.perspective(side)
+	_, _ = w.Write([]byte(GetGameTextFromGame(g) + GetFleetStatusText(g)))
+}
+
+func queryInt(r *http.Request, key string, fallback int) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 100 || i > 2000 {
+		return 0, fmt.Errorf("%s must be a number between 100 and 2000", key)
+	}
+
+	return i, nil
+}
+
+// queryFormat returns the image format named by the format query parameter, PNG by default.
+func queryFormat(r *http.Request) (ImageFormat, error) {
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		return ImageFormat{}, nil
+	}
+
+	format, ok := ImageFormatByName(name)
+	if !ok {
+		return ImageFormat{}, errUnknownFormat
+	}
+
+	return format, nil
+}
+
+// badRequest wraps errors caused by invalid input from the client.
+type badRequest struct {
+	err error
+}
+
+func (e badRequest) Error() string {
+	return e.err.Error()
+}
+
+func (e badRequest) Unwrap() error {
+	return e.err
+}
+
+func writeManagerError(w http.ResponseWriter, err error) {
+	var bad badRequest
+
+	switch {
+	case errors.Is(err, ErrGameNotFound), errors.Is(err, ErrPlayerNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, errUnauthorized):
+		writeError(w, http.StatusUnauthorized, err)
+	case errors.Is(err, errShipsAlreadyPlaced), errors.Is(err, errShipsNotPlaced),
+		errors.Is(err, errNotYourTurn), errors.Is(err, errGameFinished), errors.Is(err, ErrGameExists):
+		writeError(w, http.StatusConflict, err)
+	case errors.As(err, &bad):
+		writeError(w, http.StatusBadRequest, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("generating random token: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,261 @@
+package twittership
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+type spriteSegment int
+
+const (
+	spriteBow spriteSegment = iota
+	spriteHull
+	spriteStern
+)
+
+// spriteColumns is the number of sprites per ship type in a sprite sheet: the bow, hull
+// and stern followed by the damaged bow, hull and stern.
+const spriteColumns = 6
+
+// spriteRows is the number of ship types in a sprite sheet.
+const spriteRows = 5
+
+// SpriteSheet contains the sprites used to draw every ship type. A sprite sheet image is a
+// grid with one row per ship type, in the order Aircraft Carrier, Battleship, Submarine,
+// Cruiser and Destroyer, and six columns: bow, hull, stern, damaged bow, damaged hull and
+// damaged stern. Sprites are drawn for a horizontal ship with the bow on the left, they are
+// rotated for vertical ships so the bow is at the top, and scaled to fit a single tile.
+type SpriteSheet struct {
+	// sprites are indexed by ship type, then damaged, then segment
+	sprites [spriteRows][2][3]image.Image
+	// rotated are the same sprites rotated for vertical ships
+	rotated [spriteRows][2][3]image.Image
+}
+
+// NewSpriteSheet creates a sprite sheet from an image laid out as described on SpriteSheet.
+func NewSpriteSheet(sheet image.Image) (*SpriteSheet, error) {
+	bounds := sheet.Bounds()
+	if bounds.Dx()%spriteColumns != 0 || bounds.Dy()%spriteRows != 0 {
+		return nil, fmt.Errorf("sprite sheet size %s must be a multiple of %dx%d sprites", bounds.Size(), spriteColumns, spriteRows)
+	}
+
+	cellW, cellH := bounds.Dx()/spriteColumns, bounds.Dy()/spriteRows
+	ss := &SpriteSheet{}
+
+	for row := 0; row < spriteRows; row++ {
+		for column := 0; column < spriteColumns; column++ {
+			cell := image.Rect(column*cellW, row*cellH, (column+1)*cellW, (row+1)*cellH).Add(bounds.Min)
+			sprite := image.NewRGBA(image.Rect(0, 0, cellW, cellH))
+			draw.Draw(sprite, sprite.Bounds(), sheet, cell.Min, draw.Src)
+
+			damaged, segment := column/3, column%3
+			ss.sprites[row][damaged][segment] = sprite
+			ss.rotated[row][damaged][segment] = rotateClockwise(sprite)
+		}
+	}
+
+	return ss, nil
+}
+
+// LoadSpriteSheet decodes a sprite sheet image from r. See SpriteSheet for the layout.
+func LoadSpriteSheet(r io.Reader) (*SpriteSheet, error) {
+	sheet, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("decoding sprite sheet: %w", err)
+	}
+
+	return NewSpriteSheet(sheet)
+}
+
+// DefaultSpriteSheet draws a sprite sheet using the ship and hit colors of the theme.
+func DefaultSpriteSheet(theme Theme) *SpriteSheet {
+	ss, _ := NewSpriteSheet(DefaultSpriteSheetImage(theme))
+
+	return ss
+}
+
+// DefaultSpriteSheetImage returns the sprite sheet image drawn by DefaultSpriteSheet. It is
+// useful as a starting point for a custom sprite sheet.
+func DefaultSpriteSheetImage(theme Theme) *image.RGBA {
+	const cell = 32
+	sheet := image.NewRGBA(image.Rect(0, 0, cell*spriteColumns, cell*spriteRows))
+
+	for row := 0; row < spriteRows; row++ {
+		for column := 0; column < spriteColumns; column++ {
+			origin := image.Pt(column*cell, row*cell)
+			drawDefaultSprite(sheet, origin, cell, shipType(row), spriteSegment(column%3), column >= 3, theme)
+		}
+	}
+
+	return sheet
+}
+
+// drawDefaultSprite draws a single segment of a ship. Every ship type has a different hull
+// height and deck details so they can be told apart at a glance.
+func drawDefaultSprite(dst *image.RGBA, origin image.Point, cell int, st shipType, segment spriteSegment, damaged bool, theme Theme) {
+	hullHeights := [...]int{22, 18, 12, 16, 12}
+	hull := hullHeights[st]
+	top := (cell - hull) / 2
+
+	body := theme.Ship
+	detail := shade(theme.Ship, 0.6)
+	if damaged {
+		body = mix(theme.Ship, theme.Hit, 0.55)
+		detail = shade(theme.Hit, 0.5)
+	}
+
+	for y := 0; y < hull; y++ {
+		for x := 0; x < cell; x++ {
+			if !insideHull(st, segment, x, y, cell, hull) {
+				continue
+			}
+
+			dst.SetRGBA(origin.X+x, origin.Y+top+y, body)
+		}
+	}
+
+	centerY := origin.Y + cell/2
+	switch st {
+	case shipAircraftCarrier:
+		// Runway markings along the deck and the island on the stern
+		for x := 4; x < cell-4; x += 6 {
+			fillRect(dst, image.Rect(origin.X+x, centerY-1, origin.X+x+3, centerY+1), detail)
+		}
+
+		if segment == spriteStern {
+			fillRect(dst, image.Rect(origin.X+10, origin.Y+top+1, origin.X+20, origin.Y+top+6), detail)
+		}
+	case shipBattleship, shipCruiser:
+		// Gun turrets, the battleship has one on every segment
+		if st == shipBattleship || segment == spriteBow {
+			fillCircle(dst, image.Pt(origin.X+cell/2, centerY), hull/4+1, detail)
+			fillRect(dst, image.Rect(origin.X+cell/2-12, centerY-1, origin.X+cell/2, centerY+1), detail)
+		}
+	case shipSubmarine:
+		// Conning tower in the middle of the hull
+		if segment == spriteHull {
+			fillRect(dst, image.Rect(origin.X+cell/2-5, centerY-3, origin.X+cell/2+5, centerY+3), detail)
+		}
+	case shipDestroyer:
+		// Smoke stack
+		if segment == spriteStern {
+			fillRect(dst, image.Rect(origin.X+8, centerY-2, origin.X+14, centerY+2), detail)
+		}
+	}
+
+	if damaged {
+		// Scorch marks across the segment
+		for i := 0; i < hull; i++ {
+			x := origin.X + cell/2 - hull/2 + i
+			dst.SetRGBA(x, origin.Y+top+i, detail)
+			dst.SetRGBA(x, origin.Y+top+hull-1-i, detail)
+		}
+	}
+}
+
+// insideHull determines if the pixel at x, y is part of the hull of a horizontal ship
+// segment. The bow comes to a point, the stern is squared off, and the submarine is rounded
+// at both ends.
+func insideHull(st shipType, segment spriteSegment, x, y, cell, hull int) bool {
+	half := hull / 2
+	distance := y - half
+	if distance < 0 {
+		distance = -distance - 1
+	}
+
+	switch segment {
+	case spriteBow:
+		if st == shipSubmarine {
+			return (x-half)*(x-half)+(y-half)*(y-half) <= half*half || x >= half
+		}
+
+		// The point of the bow takes up the left half of the segment
+		return x*half >= (cell/2)*distance
+	case spriteStern:
+		if st == shipSubmarine {
+			right := cell - half
+			return (x-right)*(x-right)+(y-half)*(y-half) <= half*half || x <= right
+		}
+
+		return x < cell-2
+	default:
+		return true
+	}
+}
+
+// drawSprite draws a ship segment sprite scaled to fill the tile, leaving the grid lines
+// visible.
+func (ui userImage) drawSprite(sprites *SpriteSheet, s ship, segment, tileX, tileY int, damaged bool) {
+	part := spriteHull
+	if segment == 0 {
+		part = spriteBow
+	} else if segment == s.width-1 {
+		part = spriteStern
+	}
+
+	d := 0
+	if damaged {
+		d = 1
+	}
+
+	sprite := sprites.sprites[s.shipType][d][part]
+	if s.direction == vertical {
+		sprite = sprites.rotated[s.shipType][d][part]
+	}
+
+	tile := ui.tile(tileX, tileY).Add(ui.img.Rect.Min)
+
+	xdraw.NearestNeighbor.Scale(ui.img, tile, sprite, sprite.Bounds(), xdraw.Over, nil)
+}
+
+func rotateClockwise(src *image.RGBA) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+
+	for y := 0; y < b.Dx(); y++ {
+		for x := 0; x < b.Dy(); x++ {
+			dst.SetRGBA(x, y, src.RGBAAt(b.Min.X+y, b.Max.Y-1-x))
+		}
+	}
+
+	return dst
+}
+
+func fillCircle(dst *image.RGBA, center image.Point, radius int, c color.RGBA) {
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= radius*radius {
+				dst.SetRGBA(center.X+x, center.Y+y, c)
+			}
+		}
+	}
+}
+
+// shade darkens c by factor, where 0 is black and 1 leaves the color unchanged.
+func shade(c color.RGBA, factor float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(float64(c.R) * factor),
+		G: uint8(float64(c.G) * factor),
+		B: uint8(float64(c.B) * factor),
+		A: c.A,
+	}
+}
+
+// mix blends a towards b by amount, where 0 is a and 1 is b.
+func mix(a, b color.RGBA, amount float64) color.RGBA {
+	blend := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*amount)
+	}
+
+	return color.RGBA{
+		R: blend(a.R, b.R),
+		G: blend(a.G, b.G),
+		B: blend(a.B, b.B),
+		A: blend(a.A, b.A),
+	}
+}
@@ -0,0 +1,548 @@
+package twittership
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrPlayerNotFound is returned when no statistics have been recorded for a handle.
+var ErrPlayerNotFound = errors.New("player not found")
+
+// ErrGameNotFinished is returned when recording statistics for a game that is still being played.
+var ErrGameNotFinished = errors.New("game is not finished")
+
+// Players are the handles of the people playing each side of a game, indexed by Side.
+type Players [2]string
+
+// PlayerStats is the record of every game a player has finished.
+type PlayerStats struct {
+	Handle string `json:"handle"`
+	Games  int    `json:"games"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	// Forfeits is how many of the losses were from giving up or running out of time.
+	Forfeits int `json:"forfeits"`
+	Shots    int `json:"shots"`
+	Hits     int `json:"hits"`
+	// WinningShots is the total number of shots fired in the games that were won.
+	WinningShots int `json:"winningShots"`
+	// Openings counts how many games were started with a shot at each position.
+	Openings      map[string]int `json:"openings"`
+	CurrentStreak int            `json:"currentStreak"`
+	LongestStreak int            `json:"longestStreak"`
+	// Rating only changes in games where both sides have a handle.
+	Rating        Rating         `json:"rating"`
+	RatingHistory []RatingChange `json:"ratingHistory"`
+}
+
+// Accuracy returns the fraction of shots that hit a ship, from 0 to 1.
+func (s PlayerStats) Accuracy() float64 {
+	if s.Shots == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(s.Shots)
+}
+
+// AverageShotsToWin returns the average number of shots fired in the games that were won.
+func (s PlayerStats) AverageShotsToWin() float64 {
+	if s.Wins == 0 {
+		return 0
+	}
+
+	return float64(s.WinningShots) / float64(s.Wins)
+}
+
+// FavouriteOpenings returns up to n of the positions most often fired at first, the most
+// common first. Positions used equally often are in board order.
+func (s PlayerStats) FavouriteOpenings(n int) []string {
+	openings := make([]string, 0, len(s.Openings))
+	for position := range s.Openings {
+		openings = append(openings, position)
+	}
+
+	sort.Slice(openings, func(i, j int) bool {
+		a, b := openings[i], openings[j]
+		if s.Openings[a] != s.Openings[b] {
+			return s.Openings[a] > s.Openings[b]
+		}
+
+		return positionLess(a, b)
+	})
+
+	if len(openings) > n {
+		openings = openings[:n]
+	}
+
+	return openings
+}
+
+// positionLess orders positions such as A2 and A10 by row and then by column.
+func positionLess(a, b string) bool {
+	if a[0] != b[0] {
+		return a[0] < b[0]
+	}
+
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+
+	return a < b
+}
+
+// record adds a finished game played on side to the statistics.
+func (s *PlayerStats) record(g Game, side Side) {
+	winner, _ := g.Winner()
+
+	s.Games++
+	if winner == side {
+		s.Wins++
+		s.CurrentStreak++
+		if s.CurrentStreak > s.LongestStreak {
+			s.LongestStreak = s.CurrentStreak
+		}
+	} else {
+		s.Losses++
+		s.CurrentStreak = 0
+		if g.FinishReason() == Forfeited || g.FinishReason() == TimedOut {
+			s.Forfeits++
+		}
+	}
+
+	shots := 0
+	for _, m := range g.Moves() {
+		if m.Side != side {
+			continue
+		}
+
+		if shots == 0 {
+			if s.Openings == nil {
+				s.Openings = map[string]int{}
+			}
+			s.Openings[m.Position]++
+		}
+
+		shots++
+		if m.Hit {
+			s.Hits++
+		}
+	}
+
+	s.Shots += shots
+	if winner == side {
+		s.WinningShots += shots
+	}
+}
+
+// StatsStore persists player statistics. Implementations must be safe for concurrent use.
+type StatsStore interface {
+	// LoadPlayer returns the statistics for handle, or ErrPlayerNotFound if there are none.
+	LoadPlayer(handle string) (PlayerStats, error)
+	// SavePlayers stores the statistics of every player provided at once.
+	SavePlayers(stats ...PlayerStats) error
+	// AllPlayers returns the statistics of every player.
+	AllPlayers() ([]PlayerStats, error)
+}
+
+// MemoryStatsStore keeps player statistics in memory.
+type MemoryStatsStore struct {
+	mu      sync.RWMutex
+	players map[string]PlayerStats
+}
+
+// NewMemoryStatsStore creates an empty in memory store.
+func NewMemoryStatsStore() *MemoryStatsStore {
+	return &MemoryStatsStore{players: map[string]PlayerStats{}}
+}
+
+// LoadPlayer returns the statistics for handle.
+func (s *MemoryStatsStore) LoadPlayer(handle string) (PlayerStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats, ok := s.players[handleKey(handle)]
+	if !ok {
+		return PlayerStats{}, fmt.Errorf("loading player %s: %w", handle, ErrPlayerNotFound)
+	}
+
+	return stats.clone(), nil
+}
+
+// SavePlayers stores the statistics of every player provided.
+func (s *MemoryStatsStore) SavePlayers(stats ...PlayerStats) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, player := range stats {
+		s.players[handleKey(player.Handle)] = player.clone()
+	}
+
+	return nil
+}
+
+// AllPlayers returns the statistics of every player ordered by handle.
+func (s *MemoryStatsStore) AllPlayers() ([]PlayerStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	players := make([]PlayerStats, 0, len(s.players))
+	for _, player := range s.players {
+		players = append(players, player.clone())
+	}
+
+	sort.Slice(players, func(i, j int) bool {
+		return handleKey(players[i].Handle) < handleKey(players[j].Handle)
+	})
+
+	return players, nil
+}
+
+// FileStatsStore keeps player statistics in memory and writes all of them to a JSON file
+// whenever they change.
+type FileStatsStore struct {
+	memory *MemoryStatsStore
+	path   string
+	mu     sync.Mutex
+}
+
+// NewFileStatsStore creates a store backed by the JSON file at path, loading the statistics
+// already in it. The file is created on the first save if it doesn't exist.
+func NewFileStatsStore(path string) (*FileStatsStore, error) {
+	s := &FileStatsStore{memory: NewMemoryStatsStore(), path: path}
+
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading stats file: %w", err)
+	}
+
+	var players []PlayerStats
+	err = json.Unmarshal(data, &players)
+	if err != nil {
+		return nil, fmt.Errorf("decoding stats file: %w", err)
+	}
+
+	err = s.memory.SavePlayers(players...)
+	if err != nil {
+		return nil, fmt.Errorf("loading stats file: %w", err)
+	}
+
+	return s, nil
+}
+
+// LoadPlayer returns the statistics for handle.
+func (s *FileStatsStore) LoadPlayer(handle string) (PlayerStats, error) {
+	return s.memory.LoadPlayer(handle)
+}
+
+// AllPlayers returns the statistics of every player ordered by handle.
+func (s *FileStatsStore) AllPlayers() ([]PlayerStats, error) {
+	return s.memory.AllPlayers()
+}
+
+// SavePlayers stores the statistics of every player provided and rewrites the file.
+func (s *FileStatsStore) SavePlayers(stats ...PlayerStats) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.memory.SavePlayers(stats...)
+	if err != nil {
+		return err
+	}
+
+	players, err := s.memory.AllPlayers()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(players, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding stats file: %w", err)
+	}
+
+	err = writeFileAtomic(s.path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("writing stats file: %w", err)
+	}
+
+	return nil
+}
+
+func (s PlayerStats) clone() PlayerStats {
+	openings := make(map[string]int, len(s.Openings))
+	for position, count := range s.Openings {
+		openings[position] = count
+	}
+	s.Openings = openings
+	s.RatingHistory = append([]RatingChange(nil), s.RatingHistory...)
+
+	return s
+}
+
+// handleKey normalises a handle so @Name and name are the same player.
+func handleKey(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(handle, "@"))
+}
+
+// Stats updates player statistics and ratings in a store as games finish.
+type Stats struct {
+	mu    sync.Mutex
+	store StatsStore
+	clock Clock
+}
+
+// NewStats creates a stats recorder that persists to store.
+func NewStats(store StatsStore) *Stats {
+	return &Stats{store: store, clock: systemClock{}}
+}
+
+// SetClock replaces the clock used to time rating changes.
+func (s *Stats) SetClock(clock Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.clock = clock
+}
+
+// RecordGame adds a finished game to the statistics of both players. A side without a
+// handle isn't recorded, and the game is only rated when both sides have a handle.
+func (s *Stats) RecordGame(players Players, g Game) error {
+	return s.recordGame(players, g, true)
+}
+
+// RecordUnratedGame adds a finished game to the statistics of both players without
+// changing their ratings.
+func (s *Stats) RecordUnratedGame(players Players, g Game) error {
+	return s.recordGame(players, g, false)
+}
+
+func (s *Stats) recordGame(players Players, g Game, rated bool) error {
+	if !g.IsFinished() {
+		return ErrGameNotFinished
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The capacity is fixed so the pointers in sides stay valid
+	var sides [2]*PlayerStats
+	updated := make([]PlayerStats, 0, 2)
+	for _, side := range []Side{PlayerSide, EnemySide} {
+		handle := players[side]
+		if handle == "" {
+			continue
+		}
+
+		// A player on both sides has both results added to the same statistics
+		if side == EnemySide && sides[PlayerSide] != nil && handleKey(handle) == handleKey(players[PlayerSide]) {
+			sides[PlayerSide].record(g, side)
+			sides[side] = sides[PlayerSide]
+			continue
+		}
+
+		stats, err := s.store.LoadPlayer(handle)
+		if errors.Is(err, ErrPlayerNotFound) {
+			stats = PlayerStats{Handle: handle}
+		} else if err != nil {
+			return fmt.Errorf("recording game: %w", err)
+		}
+
+		// Statistics recorded before ratings were added start unrated
+		if stats.Rating == (Rating{}) {
+			stats.Rating = NewRating()
+		}
+
+		stats.record(g, side)
+		updated = append(updated, stats)
+		sides[side] = &updated[len(updated)-1]
+	}
+
+	if rated && sides[PlayerSide] != nil && sides[EnemySide] != nil && handleKey(players[PlayerSide]) != handleKey(players[EnemySide]) {
+		rateGame(sides, g, s.clock.Now())
+	}
+
+	err := s.store.SavePlayers(updated...)
+	if err != nil {
+		return fmt.Errorf("recording game: %w", err)
+	}
+
+	return nil
+}
+
+// Player returns the statistics for handle, or ErrPlayerNotFound if they haven't finished a game.
+func (s *Stats) Player(handle string) (PlayerStats, error) {
+	return s.store.LoadPlayer(handle)
+}
+
+// SetStats records every game in the manager in stats once it finishes. Only games
+// that have players set with SetPlayers are recorded.
+func (m *Manager) SetStats(stats *Stats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stats = stats
+}
+
+func (m *Manager) statsRecorder() *Stats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.stats
+}
+
+// playerRating returns the rating of the player from the statistics, players without one
+// have the rating of a new player.
+func (m *Manager) playerRating(handle string) (float64, error) {
+	stats := m.statsRecorder()
+	if stats == nil {
+		return defaultRating, nil
+	}
+
+	player, err := stats.Player(handle)
+	if errors.Is(err, ErrPlayerNotFound) || (err == nil && player.Rating == (Rating{})) {
+		return defaultRating, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return player.Rating.Rating, nil
+}
+
+// SetPlayers sets the handles of the people playing each side of the game with the id
+// provided.
+func (m *Manager) SetPlayers(id string, players Players) error {
+	mg, err := m.lookup(id)
+	if err != nil {
+		return err
+	}
+
+	mg.mu.Lock()
+	defer mg.mu.Unlock()
+
+	mg.players = players
+
+	return nil
+}
+
+// Players returns the handles of the people playing the game with the id provided.
+func (m *Manager) Players(id string) (Players, error) {
+	mg, err := m.lookup(id)
+	if err != nil {
+		return Players{}, err
+	}
+
+	mg.mu.Lock()
+	defer mg.mu.Unlock()
+
+	return mg.players, nil
+}
+
+// SetRated sets whether the game with the id provided changes the ratings of its players.
+// Games are rated unless they are set to be unrated.
+func (m *Manager) SetRated(id string, rated bool) error {
+	mg, err := m.lookup(id)
+	if err != nil {
+		return err
+	}
+
+	mg.mu.Lock()
+	defer mg.mu.Unlock()
+
+	mg.unrated = !rated
+
+	return nil
+}
+
+// finishedGame is a finished game waiting to be added to the player statistics.
+type finishedGame struct {
+	id      string
+	players Players
+	game    Game
+	rated   bool
+}
+
+// claimStats returns the game to record once it has finished, marking it as recorded so it
+// is only claimed once. The game must be locked.
+func (m *Manager) claimStats(id string, mg *managedGame) (finishedGame, bool) {
+	if m.statsRecorder() == nil || mg.recorded || !mg.game.IsFinished() || mg.players == (Players{}) {
+		return finishedGame{}, false
+	}
+
+	mg.recorded = true
+
+	game := mg.game.clone()
+	game.subscribers = nil
+
+	return finishedGame{id: id, players: mg.players, game: game, rated: !mg.unrated}, true
+}
+
+// recordStats adds finished games to the player statistics. It must be called without any
+// game locked as the store may be slow to save. Games that fail are queued to be retried by
+// RetryStats and the error is passed to the handler set with SetStatsErrorHandler.
+func (m *Manager) recordStats(games ...finishedGame) {
+	stats := m.statsRecorder()
+	if stats == nil {
+		return
+	}
+
+	var failed []finishedGame
+	var errs []error
+	for _, fg := range games {
+		err := stats.recordGame(fg.players, fg.game, fg.rated)
+		if err != nil {
+			failed = append(failed, fg)
+			errs = append(errs, fmt.Errorf("recording game %s: %w", fg.id, err))
+		}
+	}
+
+	if len(failed) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	m.unrecorded = append(m.unrecorded, failed...)
+	handler := m.statsErrors
+	m.mu.Unlock()
+
+	if handler == nil {
+		return
+	}
+
+	for i, fg := range failed {
+		handler(fg.id, errs[i])
+	}
+}
+
+// SetStatsErrorHandler sets a function called whenever a finished game can't be added to
+// the player statistics, I.E. to log it. The move that finished the game still succeeds
+// and the game is retried by RetryStats.
+func (m *Manager) SetStatsErrorHandler(handler func(gameID string, err error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.statsErrors = handler
+}
+
+// RetryStats tries again to add the finished games that couldn't be added to the player
+// statistics before. CheckTimeouts calls it every time the timeouts are checked.
+func (m *Manager) RetryStats() {
+	m.mu.Lock()
+	games := m.unrecorded
+	m.unrecorded = nil
+	m.mu.Unlock()
+
+	m.recordStats(games...)
+}
@@ -0,0 +1,212 @@
+package twittership
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+)
+
+// SVGOptions configures how RenderSVG draws a game.
+type SVGOptions struct {
+	// Theme selects the colors and glyphs. The zero value uses ClassicTheme.
+	Theme Theme
+	// TileSize is the size of a single tile in SVG user units. It defaults to 40, which
+	// matches the layout of a GameImage with 401x401 boards. The labels, titles and the
+	// space around the boards scale with it.
+	TileSize int
+}
+
+// svgLayout is where the frame around the boards is drawn, every size scales with the tile
+// so a tile of 40 matches the layout of a GameImage.
+type svgLayout struct {
+	tile int
+	// label is the width of the row letters beside each board
+	label int
+	// header is the height of the band with the board titles
+	header int
+	// top is the top of the boards, below the header and the column numbers
+	top int
+	// titleFont and labelFont are the font sizes of the board titles and the coordinates
+	titleFont int
+	labelFont int
+}
+
+func newSVGLayout(tile int) svgLayout {
+	return svgLayout{
+		tile:      tile,
+		label:     tile,
+		header:    tile * 5 / 4,
+		top:       tile*5/4 + tile,
+		titleFont: tile * 3 / 4,
+		labelFont: tile / 2,
+	}
+}
+
+// svgWriter writes the elements of an SVG image. The first write error is kept and every
+// write after it is skipped so the error only needs to be checked once at the end.
+type svgWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (sw *svgWriter) printf(format string, args ...interface{}) {
+	if sw.err != nil {
+		return
+	}
+
+	_, sw.err = fmt.Fprintf(sw.w, format, args...)
+}
+
+// RenderSVG writes the game to w as an SVG image. The layout is the same as a GameImage:
+// the player board with the player ships on the left and the enemy board on the right, with
+// hits, misses and sunk ships drawn on both. Unlike a GameImage it stays crisp at any size.
+func RenderSVG(g Game, opts SVGOptions, w io.Writer) error {
+	if opts.Theme.Name == "" {
+		opts.Theme = ClassicTheme
+	}
+
+	if opts.TileSize == 0 {
+		opts.TileSize = 40
+	}
+
+	tile := opts.TileSize
+	l := newSVGLayout(tile)
+	boardSize := tile*10 + 1
+	totalW, totalH := boardSize*2+2*l.label, boardSize+l.top
+	theme := opts.Theme
+
+	sw := &svgWriter{w: bufio.NewWriter(w)}
+	sw.printf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", totalW, totalH, totalW, totalH)
+	sw.printf("<rect width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", totalW, totalH, svgColor(theme.Background))
+	sw.printf("<rect width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", totalW, l.header, svgColor(theme.Header))
+	sw.printf("<line x1=\"0\" y1=\"%d.5\" x2=\"%d\" y2=\"%d.5\" stroke=\"%s\"/>\n", l.header, totalW, l.header, svgColor(theme.Grid))
+
+	boards := []struct {
+		title string
+		left  int
+		tiles [10][10]boardTile
+		ships []ship
+		own   bool
+	}{
+		{"Player Board", l.label, g.playerBoard, g.playerShips, true},
+		{"Enemy Board", boardSize + 2*l.label, g.enemyBoard, g.enemyShips, false},
+	}
+
+	for _, board := range boards {
+		sw.svgFrame(board.title, board.left, l, theme)
+		sw.printf("<g transform=\"translate(%d %d)\">\n", board.left, l.top)
+		sw.printf("<rect x=\"0.5\" y=\"0.5\" width=\"%d\" height=\"%d\" fill=\"%s\" stroke=\"%s\"/>\n", tile*10, tile*10, svgColor(theme.Water), svgColor(theme.Grid))
+
+		for i := 1; i < 10; i++ {
+			sw.printf("<line x1=\"%d.5\" y1=\"0\" x2=\"%d.5\" y2=\"%d\" stroke=\"%s\"/>\n", i*tile, i*tile, tile*10, svgColor(theme.Grid))
+			sw.printf("<line x1=\"0\" y1=\"%d.5\" x2=\"%d\" y2=\"%d.5\" stroke=\"%s\"/>\n", i*tile, tile*10, i*tile, svgColor(theme.Grid))
+		}
+
+		if board.own {
+			for _, s := range board.ships {
+				w, h := svgShipSize(s)
+				sw.printf("<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", s.x*tile+1, s.y*tile+1, w*tile-1, h*tile-1, svgColor(theme.Ship))
+			}
+		}
+
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 10; x++ {
+				t := board.tiles[y][x]
+				if t.volleyIndex == -1 {
+					continue
+				}
+
+				if t.shipIndex != -1 && board.ships[t.shipIndex].hits >= board.ships[t.shipIndex].width {
+					sw.svgTile(x, y, tile, theme.Sunk, theme.HitGlyph, theme)
+				} else if t.shipIndex != -1 {
+					sw.svgTile(x, y, tile, theme.Hit, theme.HitGlyph, theme)
+				} else {
+					sw.svgTile(x, y, tile, theme.Miss, theme.MissGlyph, theme)
+				}
+			}
+		}
+
+		// Sunk ships are outlined on both boards, revealing the full outline of sunk enemy ships
+		for _, s := range board.ships {
+			if s.hits < s.width {
+				continue
+			}
+
+			w, h := svgShipSize(s)
+			sw.printf("<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\"/>\n", s.x*tile+1, s.y*tile+1, w*tile-1, h*tile-1, svgColor(theme.Label))
+		}
+
+		sw.printf("</g>\n")
+	}
+
+	sw.printf("</svg>\n")
+
+	if sw.err == nil {
+		sw.err = sw.w.Flush()
+	}
+
+	if sw.err != nil {
+		return fmt.Errorf("unable to write game svg: %w", sw.err)
+	}
+
+	return nil
+}
+
+// svgFrame draws the title, column numbers and row letters of a board.
+func (sw *svgWriter) svgFrame(title string, left int, l svgLayout, theme Theme) {
+	tile := l.tile
+	labelLeft := left - l.label
+	center := labelLeft + (tile*10+l.label+1)/2
+
+	sw.printf("<line x1=\"%d.5\" y1=\"0\" x2=\"%d.5\" y2=\"%d\" stroke=\"%s\"/>\n", labelLeft, labelLeft, tile*10+l.top+1, svgColor(theme.Grid))
+	sw.printf("<text x=\"%d\" y=\"%d\" %s font-size=\"%d\">%s</text>\n", center, l.header/2, svgTextAttributes(theme), l.titleFont, title)
+
+	for i := 0; i < 10; i++ {
+		sw.printf("<text x=\"%d\" y=\"%d\" %s font-size=\"%d\">%d</text>\n", left+i*tile+tile/2, (l.header+l.top)/2, svgTextAttributes(theme), l.labelFont, i+1)
+		sw.printf("<text x=\"%d\" y=\"%d\" %s font-size=\"%d\">%c</text>\n", labelLeft+l.label/2, l.top+i*tile+tile/2, svgTextAttributes(theme), l.labelFont, 'A'+i)
+	}
+
+	for i := 0; i < 10; i++ {
+		sw.printf("<line x1=\"%d.5\" y1=\"%d\" x2=\"%d.5\" y2=\"%d\" stroke=\"%s\"/>\n", left+i*tile, l.header, left+i*tile, l.top, svgColor(theme.Grid))
+		sw.printf("<line x1=\"%d\" y1=\"%d.5\" x2=\"%d\" y2=\"%d.5\" stroke=\"%s\"/>\n", labelLeft, l.top+i*tile, left, l.top+i*tile, svgColor(theme.Grid))
+	}
+}
+
+// svgTile fills a tile a volley has been fired at and draws the glyph on top of it.
+func (sw *svgWriter) svgTile(x, y, tile int, fill color.RGBA, glyph GlyphStyle, theme Theme) {
+	left, top := x*tile, y*tile
+	sw.printf("<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", left+1, top+1, tile-1, tile-1, svgColor(fill))
+
+	pad := theme.GlyphPadding
+	radius := tile/2 - pad
+	cx, cy := left+tile/2, top+tile/2
+
+	switch glyph {
+	case GlyphCross:
+		sw.printf("<path d=\"M%d %dL%d %dM%d %dL%d %d\" stroke=\"%s\" stroke-width=\"%d\"/>\n",
+			left+pad, top+pad, left+tile-pad, top+tile-pad,
+			left+tile-pad, top+pad, left+pad, top+tile-pad,
+			svgColor(theme.Glyph), theme.LineWidth*3/2)
+	case GlyphDot:
+		sw.printf("<circle cx=\"%d\" cy=\"%d\" r=\"%d\" fill=\"%s\"/>\n", cx, cy, radius, svgColor(theme.Glyph))
+	case GlyphRing:
+		sw.printf("<circle cx=\"%d\" cy=\"%d\" r=\"%d\" fill=\"none\" stroke=\"%s\" stroke-width=\"%d\"/>\n", cx, cy, radius-theme.LineWidth/2, svgColor(theme.Glyph), theme.LineWidth)
+	}
+}
+
+func svgShipSize(s ship) (int, int) {
+	if s.direction == vertical {
+		return 1, s.width
+	}
+
+	return s.width, 1
+}
+
+func svgTextAttributes(theme Theme) string {
+	return fmt.Sprintf("fill=\"%s\" font-family=\"monospace\" text-anchor=\"middle\" dominant-baseline=\"central\"", svgColor(theme.Label))
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
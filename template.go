@@ -0,0 +1,94 @@
+package twittership
+
+import (
+	"bytes"
+	_ "embed" // needed to embed the default board template
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"sync"
+)
+
+//go:embed game_template.png
+var defaultTemplatePNG []byte
+
+var (
+	defaultTemplateOnce sync.Once
+	defaultTemplate     image.Image
+	defaultTemplateErr  error
+)
+
+// DefaultTemplate returns the board template embedded in the package. It is drawn for
+// 401x401 boards at a scale of 1, for any other size the frame is drawn to fit the boards
+// instead.
+func DefaultTemplate() (image.Image, error) {
+	defaultTemplateOnce.Do(func() {
+		defaultTemplate, _, defaultTemplateErr = image.Decode(bytes.NewReader(defaultTemplatePNG))
+		if defaultTemplateErr != nil {
+			defaultTemplateErr = fmt.Errorf("decoding embedded game_template: %w", defaultTemplateErr)
+		}
+	})
+
+	return defaultTemplate, defaultTemplateErr
+}
+
+// drawFrame draws everything the board template would provide: the header with the board
+// titles, the column numbers above each board, and the row letters beside each board.
+func drawFrame(img *image.RGBA, l layout, theme Theme) {
+	bounds := img.Bounds()
+	headerBottom := l.origin.Y + l.headerHeight
+	inset := 4 * l.scale
+
+	draw.Draw(img, bounds, image.NewUniform(theme.Background), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, bounds.Dx(), headerBottom), image.NewUniform(theme.Header), image.Point{}, draw.Src)
+	fillRect(img, image.Rect(0, headerBottom, bounds.Dx(), headerBottom+l.line), theme.Grid)
+
+	// Every coordinate label uses the scale at which the widest label fits
+	labelScale := largestTextScale("10", image.Pt(l.tileWidth-inset, l.numbersHeight-inset), 2*l.scale)
+	if scale := largestTextScale("J", image.Pt(l.labelWidth-inset, l.tileHeight-inset), 2*l.scale); scale < labelScale {
+		labelScale = scale
+	}
+
+	boards := []struct {
+		title string
+		area  image.Rectangle
+	}{
+		{"Player Board", l.board(PlayerSide)},
+		{"Enemy Board", l.board(EnemySide)},
+	}
+
+	for _, board := range boards {
+		labelLeft := board.area.Min.X - l.labelWidth
+		titleArea := image.Rect(labelLeft, l.origin.Y, board.area.Max.X, headerBottom)
+		drawText(img, board.title, centerOf(titleArea), largestTextScale(board.title, titleArea.Inset(inset).Size(), 3*l.scale), theme.Label)
+
+		// The line to the left of the labels, which also divides the two boards
+		fillRect(img, image.Rect(labelLeft, 0, labelLeft+l.line, board.area.Max.Y), theme.Grid)
+
+		for i := 0; i < 10; i++ {
+			left := board.area.Min.X + i*l.tileWidth
+			column := image.Rect(left, headerBottom, left+l.tileWidth, board.area.Min.Y)
+			fillRect(img, image.Rect(column.Min.X, column.Min.Y, column.Min.X+l.line, column.Max.Y), theme.Grid)
+			number := strconv.Itoa(i + 1)
+			drawText(img, number, centerOf(column), labelScale, theme.Label)
+
+			top := board.area.Min.Y + i*l.tileHeight
+			row := image.Rect(labelLeft, top, board.area.Min.X, top+l.tileHeight)
+			fillRect(img, image.Rect(row.Min.X, row.Min.Y, row.Max.X, row.Min.Y+l.line), theme.Grid)
+			letter := string(rune('A' + i))
+			drawText(img, letter, centerOf(row), labelScale, theme.Label)
+		}
+
+		fillRect(img, image.Rect(labelLeft, board.area.Min.Y, board.area.Max.X, board.area.Min.Y+l.line), theme.Grid)
+	}
+}
+
+func fillRect(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func centerOf(r image.Rectangle) image.Point {
+	return image.Pt((r.Min.X+r.Max.X)/2, (r.Min.Y+r.Max.Y)/2)
+}
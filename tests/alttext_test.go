@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"twittership"
+	"unicode/utf8"
+)
+
+func TestAltTextDescribesTheGame(t *testing.T) {
+	game := newFleetTestGame(t)
+
+	text := twittership.GetAltTextFromGame(game)
+	expected := "Battleship game seen by the Player. Last shot: the Enemy fired at A1 and hit. " +
+		"Player ships remaining: 4 of 5. Enemy ships remaining: 4 of 5. " +
+		"Enemy board: Row B hits B8. Row H hits H8, H9. Row J misses J1. " +
+		"Player board: Row A hits A1. Row E hits E3, E4, E5."
+
+	if text != expected {
+		t.Fatalf("expected alt text:\n%s\nbut got:\n%s", expected, text)
+	}
+}
+
+func TestAltTextOnlyDescribesWhatTheSideCanSee(t *testing.T) {
+	game := newFleetTestGame(t)
+
+	text := game.View(twittership.EnemySide).AltText()
+	if !strings.HasPrefix(text, "Battleship game seen by the Enemy.") {
+		t.Errorf("expected the alt text to be from the enemy side but got: %s", text)
+	}
+
+	if !strings.Contains(text, "Player board: Row A hits A1. Row E hits E3, E4, E5. Enemy board: Row B hits B8.") {
+		t.Errorf("expected the opponent board to be described first but got: %s", text)
+	}
+}
+
+func TestAltTextWithoutShots(t *testing.T) {
+	game := twittership.NewGame()
+
+	text := twittership.GetAltTextFromGame(game)
+	if !strings.Contains(text, "No shots have been fired.") || !strings.HasSuffix(text, "Player board: no shots.") {
+		t.Errorf("expected the alt text to describe an empty game but got: %s", text)
+	}
+}
+
+func TestAltTextFitsTheLimitForLongGames(t *testing.T) {
+	game := newImageTestGame(t)
+
+	for y := 2; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			position := fmt.Sprintf("%c%d", 'A'+y, x+1)
+			if position == "H9" {
+				continue
+			}
+
+			_, err := game.PlayerVolley(position)
+			if err != nil {
+				t.Fatalf("firing player volley %s: %v", position, err)
+			}
+
+			_, err = game.EnemyVolley(position)
+			if err != nil {
+				t.Fatalf("firing enemy volley %s: %v", position, err)
+			}
+		}
+	}
+
+	text := twittership.GetAltTextFromGame(game)
+	if utf8.RuneCountInString(text) > twittership.MaxAltTextLength {
+		t.Fatalf("expected the alt text to fit in %d characters but it was %d", twittership.MaxAltTextLength, utf8.RuneCountInString(text))
+	}
+
+	if !strings.Contains(text, "Row C hits") || strings.Contains(text, "misses C") {
+		t.Errorf("expected a shorter row by row summary but got: %s", text)
+	}
+}
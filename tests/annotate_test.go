@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"image"
+	"testing"
+	"twittership"
+)
+
+func TestGameImageHighlightsTheLastMoveOfEachSide(t *testing.T) {
+	t.Parallel()
+
+	gameImage, err := twittership.NewGameImageWithOptions(newReplayTestGame(t), 401, 401, twittership.ImageOptions{HighlightLastMoves: true})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	img := gameImage.GetFullImage()
+	highlight := twittership.ClassicTheme.Highlight
+
+	// Both sides fired at J10 last, the player shot is on the enemy board to the right
+	if countTileColor(img, 9, 9, highlight) == 0 || countTileColor(img, 20, 9, highlight) == 0 {
+		t.Errorf("expected the last shot of each side to be highlighted")
+	}
+
+	if countTileColor(img, 19, 7, highlight) != 0 {
+		t.Errorf("expected earlier shots not to be highlighted")
+	}
+}
+
+func TestGameImageCanNumberEveryShot(t *testing.T) {
+	t.Parallel()
+
+	game := newReplayTestGame(t)
+	label := twittership.ClassicTheme.Label
+
+	plain, err := twittership.NewGameImageWithOptions(game, 401, 401, twittership.ImageOptions{})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	numbered, err := twittership.NewGameImageWithOptions(game, 401, 401, twittership.ImageOptions{ShotNumbers: true})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	// The player miss at J10 on the enemy board only has label colored pixels once it is numbered
+	if countTileColor(numbered.GetFullImage(), 20, 9, label) <= countTileColor(plain.GetFullImage(), 20, 9, label) {
+		t.Errorf("expected the shot number to be drawn on the tile")
+	}
+
+	if countTileColor(numbered.GetFullImage(), 15, 5, label) != countTileColor(plain.GetFullImage(), 15, 5, label) {
+		t.Errorf("expected tiles that haven't been fired at not to be numbered")
+	}
+}
+
+func TestGameImageCanCaptionTheLastMove(t *testing.T) {
+	t.Parallel()
+
+	gameImage, err := twittership.NewGameImageWithOptions(newReplayTestGame(t), 401, 401, twittership.ImageOptions{Caption: true})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	img := gameImage.GetFullImage()
+	if img.Bounds() != image.Rect(0, 0, 882, 531) {
+		t.Fatalf("expected the caption to be drawn below the boards but the image is %s", img.Bounds())
+	}
+
+	if img.RGBAAt(5, 520) != twittership.ClassicTheme.Header {
+		t.Errorf("expected the caption to use the header color but got %v", img.RGBAAt(5, 520))
+	}
+
+	found := false
+	for x := 0; x < 882 && !found; x++ {
+		for y := 492; y < 531 && !found; y++ {
+			found = img.RGBAAt(x, y) == twittership.ClassicTheme.Label
+		}
+	}
+
+	if !found {
+		t.Errorf("expected the caption text to be drawn")
+	}
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"twittership"
+)
+
+func TestEmojiGridShowsEachBoard(t *testing.T) {
+	game := newFleetTestGame(t)
+
+	player := strings.Split(twittership.GetGameEmojiFromGame(game, twittership.EmojiOptions{Boards: twittership.PlayerBoardOnly}), "\n")
+	if len(player) != 10 {
+		t.Fatalf("expected 10 rows but got %d", len(player))
+	}
+
+	if player[0] != "💥🚢🚢🚢🚢🌊🌊🌊🌊🌊" {
+		t.Errorf("expected the damaged aircraft carrier on row A but got %s", player[0])
+	}
+
+	if player[4] != "🌊🌊☠️☠️☠️🌊🌊🚢🌊🌊" {
+		t.Errorf("expected the sunk submarine on row E but got %s", player[4])
+	}
+
+	enemy := strings.Split(twittership.GetGameEmojiFromGame(game, twittership.EmojiOptions{Boards: twittership.EnemyBoardOnly}), "\n")
+	expected := map[int]string{
+		0: "🌊🌊🌊🌊🌊🌊🌊🌊🌊🌊",
+		1: "🌊🌊🌊🌊🌊🌊🌊💥🌊🌊",
+		7: "🌊🌊🌊🌊🌊🌊🌊☠️☠️🌊",
+		9: "⚪🌊🌊🌊🌊🌊🌊🌊🌊🌊",
+	}
+
+	for row, line := range expected {
+		if enemy[row] != line {
+			t.Errorf("expected enemy row %c to be %s but got %s", 'A'+row, line, enemy[row])
+		}
+	}
+
+	both := strings.Split(twittership.GetGameEmojiFromGame(game, twittership.EmojiOptions{Labels: true}), "\n")
+	if len(both) != 11 || both[1] != "🇦"+player[0]+" 🇦"+enemy[0] {
+		t.Errorf("expected both boards side by side with labels but got:\n%s", strings.Join(both, "\n"))
+	}
+}
+
+func TestTweetEmojiAlwaysFitsInATweet(t *testing.T) {
+	game := newImageTestGame(t)
+
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			position := fmt.Sprintf("%c%d", 'A'+y, x+1)
+			if position == "H9" || position == "A1" || position == "A2" || position == "B1" || position == "B2" {
+				continue
+			}
+
+			_, err := game.PlayerVolley(position)
+			if err != nil {
+				t.Fatalf("firing player volley %s: %v", position, err)
+			}
+
+			_, err = game.EnemyVolley(position)
+			if err != nil {
+				t.Fatalf("firing enemy volley %s: %v", position, err)
+			}
+		}
+	}
+
+	for _, side := range []twittership.Side{twittership.PlayerSide, twittership.EnemySide} {
+		text := twittership.GetTweetEmojiFromGame(game, side)
+		if length := twittership.TweetLength(text); length > twittership.MaxTweetLength {
+			t.Errorf("expected the %s tweet to fit but it was %d characters:\n%s", side, length, text)
+		}
+
+		if strings.Contains(text, "🚢") {
+			t.Errorf("expected the %s tweet not to reveal ships:\n%s", side, text)
+		}
+	}
+}
+
+func TestTweetLengthCountsLikeTwitter(t *testing.T) {
+	lengths := map[string]int{
+		"Hello":    5,
+		"🌊":        2,
+		"☠️":       2,
+		"1️⃣":      2,
+		"🇺🇸":       2,
+		"👨‍👩‍👧":    2,
+		"A1 💥 Hit": 9,
+	}
+
+	for text, expected := range lengths {
+		if length := twittership.TweetLength(text); length != expected {
+			t.Errorf("expected %q to count as %d but got %d", text, expected, length)
+		}
+	}
+}
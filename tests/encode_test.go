@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"twittership"
+	"twittership/tests/imagetest"
+)
+
+func TestGameImageCanBeEncodedInEveryFormat(t *testing.T) {
+	t.Parallel()
+
+	gameImage, err := twittership.NewGameImageWithOptions(newImageTestGame(t), 401, 401, twittership.ImageOptions{})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	formats := []struct {
+		name        string
+		format      twittership.ImageFormat
+		contentType string
+		decode      func(r *bytes.Reader) (image.Image, error)
+	}{
+		{"png", twittership.ImageFormat{PNGCompression: png.BestCompression}, "image/png", func(r *bytes.Reader) (image.Image, error) { return png.Decode(r) }},
+		{"jpeg", twittership.ImageFormat{Encoding: twittership.EncodingJPEG, JPEGQuality: 95}, "image/jpeg", func(r *bytes.Reader) (image.Image, error) { return jpeg.Decode(r) }},
+		{"gif", twittership.ImageFormat{Encoding: twittership.EncodingGIF}, "image/gif", func(r *bytes.Reader) (image.Image, error) { return gif.Decode(r) }},
+	}
+
+	for _, f := range formats {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := gameImage.Encode(&buf, f.format)
+			if err != nil {
+				t.Fatalf("encoding image: %v", err)
+			}
+
+			img, err := f.decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("decoding image: %v", err)
+			}
+
+			if img.Bounds() != gameImage.GetFullImage().Bounds() {
+				t.Fatalf("unexpected image bounds %s", img.Bounds())
+			}
+
+			if f.format.ContentType() != f.contentType {
+				t.Fatalf("expected content type %s but got %s", f.contentType, f.format.ContentType())
+			}
+
+			byName, ok := twittership.ImageFormatByName(f.name)
+			if !ok || byName.Encoding != f.format.Encoding {
+				t.Fatalf("format %s could not be found by name", f.name)
+			}
+		})
+	}
+}
+
+func TestGameImageGIFsKeepTheThemeColors(t *testing.T) {
+	t.Parallel()
+
+	gameImage, err := twittership.NewGameImageWithOptions(newImageTestGame(t), 401, 401, twittership.ImageOptions{})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = gameImage.Encode(&buf, twittership.ImageFormat{Encoding: twittership.EncodingGIF})
+	if err != nil {
+		t.Fatalf("encoding image: %v", err)
+	}
+
+	img, err := gif.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decoding image: %v", err)
+	}
+
+	imagetest.AssertSame(t, gameImage.GetFullImage(), img, imagetest.Options{})
+}
+
+func TestGameImageReturnsAnErrorForAnUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	gameImage, err := twittership.NewGameImageWithOptions(newImageTestGame(t), 401, 401, twittership.ImageOptions{})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	err = gameImage.Encode(ioutil.Discard, twittership.ImageFormat{Encoding: twittership.Encoding(42)})
+	if !errors.Is(err, twittership.ErrUnknownImageFormat) {
+		t.Fatalf("expected ErrUnknownImageFormat but got %v", err)
+	}
+
+	if _, ok := twittership.ImageFormatByName("bmp"); ok {
+		t.Fatalf("expected bmp not to be a known format")
+	}
+}
+
+func TestGameImageOverwritesLargerFiles(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "twittership")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "board.png")
+	err = ioutil.WriteFile(filename, bytes.Repeat([]byte("garbage"), 100000), 0600)
+	if err != nil {
+		t.Fatalf("writing existing file: %v", err)
+	}
+
+	gameImage, err := twittership.NewGameImageWithOptions(newImageTestGame(t), 401, 401, twittership.ImageOptions{})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	err = gameImage.WriteImage(filename)
+	if err != nil {
+		t.Fatalf("writing image: %v", err)
+	}
+
+	var expected bytes.Buffer
+	err = gameImage.Encode(&expected, twittership.ImageFormat{})
+	if err != nil {
+		t.Fatalf("encoding image: %v", err)
+	}
+
+	written, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("reading image: %v", err)
+	}
+
+	if !bytes.Equal(written, expected.Bytes()) {
+		t.Fatalf("expected the file to only contain the new image, got %d bytes instead of %d", len(written), expected.Len())
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("reading temp dir: %v", err)
+	}
+
+	if len(files) != 1 {
+		t.Fatalf("expected no temporary files to be left behind but found %d files", len(files))
+	}
+}
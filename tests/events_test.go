@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"twittership"
+)
+
+func describeEvent(e twittership.Event) string {
+	switch e := e.(type) {
+	case twittership.ShipPlacedEvent:
+		return fmt.Sprintf("placed %s %s", e.Side, e.Ship.Name)
+	case twittership.ShotFiredEvent:
+		return fmt.Sprintf("shot %s %s", e.Move.Side, e.Move.Position)
+	case twittership.HitEvent:
+		return fmt.Sprintf("hit %s", e.Move.Position)
+	case twittership.MissEvent:
+		return fmt.Sprintf("miss %s", e.Move.Position)
+	case twittership.SunkEvent:
+		return fmt.Sprintf("sunk %s %s", e.Owner, e.Ship)
+	case twittership.GameOverEvent:
+		return fmt.Sprintf("game over %s %s", e.Winner, e.Reason)
+	case twittership.TurnChangedEvent:
+		return fmt.Sprintf("turn %s", e.Turn)
+	default:
+		return fmt.Sprintf("unknown %T", e)
+	}
+}
+
+func expectEvents(t *testing.T, actual []string, expected ...string) {
+	t.Helper()
+
+	if len(actual) != len(expected) {
+		t.Fatalf("expected events %q but got %q", expected, actual)
+	}
+
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("expected events %q but got %q", expected, actual)
+		}
+	}
+}
+
+func TestGameDeliversEventsForEveryAction(t *testing.T) {
+	g := twittership.NewGame()
+
+	var events []string
+	g.Subscribe(func(e twittership.Event) {
+		events = append(events, describeEvent(e))
+	})
+
+	err := g.LoadPlayerShips("A1H;B8V;E3H;G3V;H8H")
+	if err != nil {
+		t.Fatalf("load player ships: %v", err)
+	}
+
+	expectEvents(t, events,
+		"placed Player Aircraft Carrier",
+		"placed Player Battleship",
+		"placed Player Submarine",
+		"placed Player Cruiser",
+		"placed Player Destroyer",
+		"turn Enemy",
+	)
+
+	events = nil
+	err = g.LoadEnemyShips("A1H;B8V;E3H;G3V;H8H")
+	if err != nil {
+		t.Fatalf("load enemy ships: %v", err)
+	}
+
+	err = g.LoadPlayerVolleys("A1;A2;A3;A4;A5;B8;C8;D8;E8;E3;E4;E5;G3;H3;I3")
+	if err != nil {
+		t.Fatalf("load player volleys: %v", err)
+	}
+
+	err = g.LoadEnemyVolleys("J1;J2;J3;J4;J5;J6;J7;J8;J9;J10;I10;H10;G10;F10;E10")
+	if err != nil {
+		t.Fatalf("load enemy volleys: %v", err)
+	}
+
+	events = nil
+	_, err = g.PlayerVolley("H8")
+	if err != nil {
+		t.Fatalf("player volley: %v", err)
+	}
+
+	expectEvents(t, events, "shot Player H8", "hit H8", "turn Enemy")
+
+	events = nil
+	_, err = g.EnemyVolley("F9")
+	if err != nil {
+		t.Fatalf("enemy volley: %v", err)
+	}
+
+	expectEvents(t, events, "shot Enemy F9", "miss F9", "turn Player")
+
+	events = nil
+	_, err = g.PlayerVolley("H9")
+	if err != nil {
+		t.Fatalf("player volley: %v", err)
+	}
+
+	expectEvents(t, events, "shot Player H9", "hit H9", "sunk Enemy Destroyer", "game over Player All Ships Sunk")
+}
+
+func TestGameStopsDeliveringEventsAfterUnsubscribing(t *testing.T) {
+	g := twittership.NewGame()
+
+	var first, second []string
+	unsubscribe := g.Subscribe(func(e twittership.Event) {
+		first = append(first, describeEvent(e))
+	})
+	g.Subscribe(func(e twittership.Event) {
+		second = append(second, describeEvent(e))
+	})
+
+	err := g.LoadEnemyShips("A1H;B8V;E3H;G3V;H8H")
+	if err != nil {
+		t.Fatalf("load enemy ships: %v", err)
+	}
+
+	unsubscribe()
+	err = g.Forfeit(twittership.PlayerSide, twittership.Forfeited)
+	if err != nil {
+		t.Fatalf("forfeit: %v", err)
+	}
+
+	if len(first) != 5 {
+		t.Fatalf("expected the first subscriber to only receive the ship placements but got %q", first)
+	}
+
+	if second[len(second)-1] != "game over Enemy Forfeited" {
+		t.Fatalf("expected the second subscriber to receive the game over event but got %q", second)
+	}
+}
@@ -0,0 +1,226 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image/png"
+	"net/http"
+	"testing"
+	"time"
+	"twittership"
+)
+
+func TestSpectatorViewOnlyShowsSunkShips(t *testing.T) {
+	t.Parallel()
+
+	game := newReplayTestGame(t)
+	view := game.SpectatorView()
+
+	// Both sides sunk the ship at H8 but no other ship
+	for _, board := range []twittership.BoardView{view.Player, view.Enemy} {
+		if len(board.Ships) != 1 || !board.Ships[0].Sunk || board.Ships[0].Position != "H8H" {
+			t.Fatalf("expected only the sunk ship at H8H to be visible but got %+v", board.Ships)
+		}
+	}
+
+	if len(view.Player.Shots) != 4 || len(view.Enemy.Shots) != 4 {
+		t.Fatalf("expected 4 shots on each board but got %d and %d", len(view.Player.Shots), len(view.Enemy.Shots))
+	}
+
+	for _, m := range view.Enemy.Shots {
+		if m.Side != twittership.PlayerSide {
+			t.Errorf("expected the enemy board to only have player shots but got %+v", m)
+		}
+	}
+}
+
+func TestSpectatorImageHidesThePlayerShips(t *testing.T) {
+	t.Parallel()
+
+	game := newImageTestGame(t)
+
+	gameImage, err := twittership.NewGameImageWithOptions(game, 401, 401, twittership.ImageOptions{Spectator: true})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	// A3 is an undamaged part of the aircraft carrier
+	if countTileColor(gameImage.GetFullImage(), 2, 0, twittership.ClassicTheme.Ship) != 0 {
+		t.Errorf("expected spectators not to see ships that haven't been sunk")
+	}
+
+	// A2 has been hit which spectators can see
+	if countTileColor(gameImage.GetFullImage(), 1, 0, twittership.ClassicTheme.Hit) == 0 {
+		t.Errorf("expected spectators to see hits")
+	}
+}
+
+func newFeedManager(t *testing.T) (*twittership.Manager, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)}
+	m := twittership.NewManager()
+	m.SetClock(clock)
+
+	for _, id := range []string{"first", "second", "idle"} {
+		err := m.Create(id)
+		if err != nil {
+			t.Fatalf("creating game %s: %v", id, err)
+		}
+
+		err = m.Do(id, func(g *twittership.Game) error {
+			err := g.LoadPlayerShips("A1H;B8V;E3H;G3V;H8H")
+			if err != nil {
+				return err
+			}
+
+			return g.LoadEnemyShips("A1H;B8V;E3H;G3V;H8H")
+		})
+		if err != nil {
+			t.Fatalf("loading ships: %v", err)
+		}
+	}
+
+	volleys := []struct {
+		id       string
+		position string
+	}{
+		{"first", "A1"},
+		{"second", "C3"},
+		{"first", "D4"},
+	}
+
+	for _, v := range volleys {
+		clock.Advance(time.Minute)
+
+		err := m.Do(v.id, func(g *twittership.Game) error {
+			_, err := g.Volley(g.Turn(), v.position)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("firing volley %s in %s: %v", v.position, v.id, err)
+		}
+	}
+
+	return m, clock
+}
+
+func TestManagerFeedListsTheNewestMovesFirst(t *testing.T) {
+	t.Parallel()
+
+	m, _ := newFeedManager(t)
+
+	feed := m.Feed(0)
+	if len(feed) != 3 {
+		t.Fatalf("expected 3 moves in the feed but got %d", len(feed))
+	}
+
+	expected := []string{"first D4", "second C3", "first A1"}
+	for i, entry := range feed {
+		if got := entry.GameID + " " + entry.Move.Position; got != expected[i] {
+			t.Errorf("expected feed entry %d to be %s but got %s", i, expected[i], got)
+		}
+	}
+
+	if !feed[0].Time.After(feed[1].Time) {
+		t.Errorf("expected each move to have the time it was made")
+	}
+
+	if feed = m.Feed(2); len(feed) != 2 {
+		t.Errorf("expected the feed to be limited to 2 moves but got %d", len(feed))
+	}
+}
+
+func TestManagerLiveGamesSkipsIdleAndFinishedGames(t *testing.T) {
+	t.Parallel()
+
+	m, _ := newFeedManager(t)
+
+	err := m.Do("second", func(g *twittership.Game) error {
+		return g.Forfeit(twittership.PlayerSide, twittership.Forfeited)
+	})
+	if err != nil {
+		t.Fatalf("forfeiting game: %v", err)
+	}
+
+	live := m.LiveGames(0)
+	if len(live) != 1 || live[0].ID != "first" {
+		t.Fatalf("expected only the first game to be live but got %+v", live)
+	}
+
+	if live[0].LastMove.Move.Position != "D4" {
+		t.Errorf("expected the last move to be D4 but got %s", live[0].LastMove.Move.Position)
+	}
+}
+
+func TestLiveDigestImageDrawsAGridOfGames(t *testing.T) {
+	t.Parallel()
+
+	_, err := twittership.NewLiveDigestImage(twittership.NewManager(), twittership.DigestOptions{})
+	if !errors.Is(err, twittership.ErrNoLiveGames) {
+		t.Fatalf("expected ErrNoLiveGames without any live games but got %v", err)
+	}
+
+	m, _ := newFeedManager(t)
+
+	one, err := twittership.NewLiveDigestImage(m, twittership.DigestOptions{Games: 1})
+	if err != nil {
+		t.Fatalf("creating digest image: %v", err)
+	}
+
+	two, err := twittership.NewLiveDigestImage(m, twittership.DigestOptions{})
+	if err != nil {
+		t.Fatalf("creating digest image: %v", err)
+	}
+
+	oneSize, twoSize := one.GetFullImage().Bounds().Size(), two.GetFullImage().Bounds().Size()
+	if twoSize.X != 2*oneSize.X || twoSize.Y != oneSize.Y {
+		t.Errorf("expected two games side by side to be twice as wide as one game but got %s and %s", oneSize, twoSize)
+	}
+}
+
+func TestServerSpectatorRoutesNeedNoToken(t *testing.T) {
+	t.Parallel()
+
+	server, game := newTestServerGame(t)
+	defer server.Close()
+
+	res, body := doRequest(t, server, http.MethodPost, "/games/"+game.ID+"/volleys", game.PlayerToken, `{"position": "A1"}`)
+	expectStatus(t, res, body, http.StatusOK)
+
+	res, body = doRequest(t, server, http.MethodGet, "/games/"+game.ID+"/spectate", "", "")
+	expectStatus(t, res, body, http.StatusOK)
+
+	var view twittership.SpectatorView
+	err := json.Unmarshal(body, &view)
+	if err != nil {
+		t.Fatalf("decoding view: %v", err)
+	}
+
+	if len(view.Enemy.Shots) != 1 || len(view.Player.Ships) != 0 {
+		t.Errorf("expected one shot and no visible ships but got %+v", view)
+	}
+
+	res, body = doRequest(t, server, http.MethodGet, "/games/"+game.ID+"/spectate.png", "", "")
+	expectStatus(t, res, body, http.StatusOK)
+
+	_, err = png.Decode(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("decoding spectator image: %v", err)
+	}
+
+	res, body = doRequest(t, server, http.MethodGet, "/feed?limit=5", "", "")
+	expectStatus(t, res, body, http.StatusOK)
+
+	var feed []twittership.FeedEntry
+	err = json.Unmarshal(body, &feed)
+	if err != nil {
+		t.Fatalf("decoding feed: %v", err)
+	}
+
+	if len(feed) != 1 || feed[0].GameID != game.ID {
+		t.Errorf("expected the feed to have the move just made but got %+v", feed)
+	}
+
+	res, body = doRequest(t, server, http.MethodGet, "/feed.png", "", "")
+	expectStatus(t, res, body, http.StatusOK)
+}
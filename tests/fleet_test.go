@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"twittership"
+)
+
+// newFleetTestGame creates a game where the player sinks the enemy destroyer and damages
+// the enemy battleship, and the enemy sinks the player submarine and damages the player
+// aircraft carrier.
+func newFleetTestGame(t *testing.T) twittership.Game {
+	return newTestGame(t, "H8;H9;B8;J1", "E3;E4;E5;A1")
+}
+
+func TestFleetStatusListsEveryShip(t *testing.T) {
+	game := newFleetTestGame(t)
+
+	expected := []twittership.ShipStatus{twittership.Damaged, twittership.Afloat, twittership.Sunk, twittership.Afloat, twittership.Afloat}
+	fleet := game.FleetStatus(twittership.PlayerSide, false)
+	if len(fleet) != len(expected) {
+		t.Fatalf("expected %d ships but got %d", len(expected), len(fleet))
+	}
+
+	for i, entry := range fleet {
+		if entry.Status != expected[i] {
+			t.Errorf("expected %s to be %s but it was %s", entry.Name, expected[i], entry.Status)
+		}
+	}
+}
+
+func TestFleetStatusHidesDamageFromTheOpponent(t *testing.T) {
+	game := newFleetTestGame(t)
+
+	fleet := game.FleetStatus(twittership.EnemySide, false)
+	if fleet[1].Status != twittership.Damaged {
+		t.Fatalf("expected the enemy battleship to be damaged but it was %s", fleet[1].Status)
+	}
+
+	fleet = game.FleetStatus(twittership.EnemySide, true)
+	if fleet[1].Status != twittership.Afloat {
+		t.Errorf("expected the opponent to see the enemy battleship afloat but it was %s", fleet[1].Status)
+	}
+
+	if fleet[4].Status != twittership.Sunk {
+		t.Errorf("expected the opponent to see the enemy destroyer sunk but it was %s", fleet[4].Status)
+	}
+}
+
+func TestGameTextShowsSunkShipsOnBothBoards(t *testing.T) {
+	game := newFleetTestGame(t)
+
+	text := twittership.GetGameTextFromGame(game)
+	lines := strings.Split(text, "\n")
+	sunk := "\x1b[45m#\x1b[0m"
+
+	// Row E has the sunk player submarine and row H has the sunk enemy destroyer
+	if strings.Count(lines[8], sunk) != 3 {
+		t.Errorf("expected the sunk submarine on row E but got %q", lines[8])
+	}
+
+	if strings.Count(lines[11], sunk) != 2 {
+		t.Errorf("expected the sunk destroyer on row H but got %q", lines[11])
+	}
+
+	if strings.Count(text, sunk) != 5 {
+		t.Errorf("expected only the sunk ships to be shown as sunk but got:\n%s", text)
+	}
+}
+
+func TestFleetStatusText(t *testing.T) {
+	game := newFleetTestGame(t)
+
+	text := twittership.GetFleetStatusText(game)
+	expected := []string{
+		"| Carrier Damaged     | | Carrier Afloat      |",
+		"| Submarine Sunk      | | Submarine Afloat    |",
+		"| Destroyer Afloat    | | Destroyer Sunk      |",
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("expected the fleet status to contain %q but got:\n%s", line, text)
+		}
+	}
+}
@@ -41,6 +41,36 @@ var validPositionStrings = []struct {
 			},
 		},
 	},
+	{
+		name:      "positions along the right and bottom edges",
+		positions: "A6H;B10V;J1H;H5V;J9H",
+		expected: [2][10][10]int{
+			{
+				{-1, -1, -1, -1, -1, 0, 0, 0, 0, 0},
+				{-1, -1, -1, -1, -1, -1, -1, -1, -1, 1},
+				{-1, -1, -1, -1, -1, -1, -1, -1, -1, 1},
+				{-1, -1, -1, -1, -1, -1, -1, -1, -1, 1},
+				{-1, -1, -1, -1, -1, -1, -1, -1, -1, 1},
+				{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+				{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+				{-1, -1, -1, -1, 3, -1, -1, -1, -1, -1},
+				{-1, -1, -1, -1, 3, -1, -1, -1, -1, -1},
+				{2, 2, 2, -1, 3, -1, -1, -1, 4, 4},
+			},
+			{
+				{-1, -1, -1, -1, -1, 0, 0, 0, 0, 0},
+				{-1, -1, -1, -1, -1, -1, -1, -1, -1, 1},
+				{-1, -1, -1, -1, -1, -1, -1, -1, -1, 1},
+				{-1, -1, -1, -1, -1, -1, -1, -1, -1, 1},
+				{-1, -1, -1, -1, -1, -1, -1, -1, -1, 1},
+				{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+				{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+				{-1, -1, -1, -1, 3, -1, -1, -1, -1, -1},
+				{-1, -1, -1, -1, 3, -1, -1, -1, -1, -1},
+				{2, 2, 2, -1, 3, -1, -1, -1, 4, 4},
+			},
+		},
+	},
 }
 
 func TestGameIsAbleToLoadShipsFromAValidPositionString(t *testing.T) {
@@ -526,3 +556,172 @@ func compareBoardOutput(actual [2][10][10]int, expected [2][10][10]int) (bool, s
 
 	return boardsEqual, output
 }
+
+func TestGameIsFinishedWhenAllEnemyShipsAreSunk(t *testing.T) {
+	g := twittership.NewGame()
+	err := g.LoadPlayerShips("A1H;B8V;E3H;G3V;H8H")
+	if err != nil {
+		t.Fatalf("load player ships: %v", err)
+	}
+
+	err = g.LoadEnemyShips("A1H;B8V;E3H;G3V;H8H")
+	if err != nil {
+		t.Fatalf("load enemy ships: %v", err)
+	}
+
+	err = g.LoadPlayerVolleys("A1;A2;A3;A4;A5;B8;C8;D8;E8;E3;E4;E5;G3;H3;I3;H8")
+	if err != nil {
+		t.Fatalf("load player volleys: %v", err)
+	}
+
+	if g.IsFinished() {
+		t.Fatalf("game should not be finished while the destroyer is afloat")
+	}
+
+	response, err := g.PlayerVolley("H9")
+	if err != nil {
+		t.Fatalf("player volley: %v", err)
+	}
+
+	if response != "You sunk my Destroyer" {
+		t.Fatalf("expected response to be \"You sunk my Destroyer\" but it was %s", response)
+	}
+
+	winner, finished := g.Winner()
+	if !finished || winner != twittership.PlayerSide || g.FinishReason() != twittership.AllShipsSunk {
+		t.Fatalf("expected the player to win by sinking all ships but got winner: %s finished: %v reason: %s", winner, finished, g.FinishReason())
+	}
+
+	_, err = g.EnemyVolley("A1")
+	if err == nil {
+		t.Fatalf("expected volleys to be rejected after the game is finished")
+	}
+}
+
+func TestGameRejectsAVolleyAtAPositionAlreadyFiredAt(t *testing.T) {
+	g := twittership.NewGame()
+	err := g.LoadEnemyShips("A1H;B8V;E3H;G3V;H8H")
+	if err != nil {
+		t.Fatalf("load enemy ships: %v", err)
+	}
+
+	_, err = g.PlayerVolley("A1")
+	if err != nil {
+		t.Fatalf("player volley: %v", err)
+	}
+
+	_, err = g.PlayerVolley("A1")
+	if err == nil {
+		t.Fatalf("expected a second volley at A1 to be rejected")
+	}
+
+	if g.GetVolleyMap()[0][0][0] != 1 {
+		t.Fatalf("the rejected volley should not have changed the existing hit")
+	}
+}
+
+func TestGetVolleyMapShowsTheVolleysEachSideFired(t *testing.T) {
+	g := twittership.NewGame()
+	err := g.LoadPlayerShips("A1H;B8V;E3H;G3V;H8H")
+	if err != nil {
+		t.Fatalf("load player ships: %v", err)
+	}
+
+	err = g.LoadEnemyShips("A6H;B10V;J1H;H5V;J9H")
+	if err != nil {
+		t.Fatalf("load enemy ships: %v", err)
+	}
+
+	// The player hits the enemy carrier at A10 and the enemy misses the player at J10
+	_, err = g.PlayerVolley("A10")
+	if err != nil {
+		t.Fatalf("player volley: %v", err)
+	}
+
+	_, err = g.EnemyVolley("J10")
+	if err != nil {
+		t.Fatalf("enemy volley: %v", err)
+	}
+
+	volleys := g.GetVolleyMap()
+	if volleys[0][0][9] != 1 || volleys[0][9][9] != -1 {
+		t.Errorf("expected the player volleys to show the hit at A10 but got %v", volleys[0])
+	}
+
+	if volleys[1][9][9] != 0 || volleys[1][0][9] != -1 {
+		t.Errorf("expected the enemy volleys to show the miss at J10 but got %v", volleys[1])
+	}
+}
+
+func TestGameVolleyJustPastTheEndOfAShipIsAMiss(t *testing.T) {
+	g := twittership.NewGame()
+	err := g.LoadEnemyShips("A1H;B8V;E3H;G3V;H8H")
+	if err != nil {
+		t.Fatalf("load enemy ships: %v", err)
+	}
+
+	// The aircraft carrier covers A1 to A5 and the battleship B8 to E8
+	for _, position := range []string{"A6", "F8"} {
+		response, err := g.PlayerVolley(position)
+		if err != nil {
+			t.Fatalf("player volley %s: %v", position, err)
+		}
+
+		if response != "Miss" {
+			t.Errorf("expected a volley at %s to miss but got %s", position, response)
+		}
+	}
+
+	response, err := g.PlayerVolley("E8")
+	if err != nil {
+		t.Fatalf("player volley: %v", err)
+	}
+
+	if response != "Hit" {
+		t.Errorf("expected a volley at E8 to hit the end of the battleship but got %s", response)
+	}
+}
+
+func TestGameTurnAlternatesBetweenSides(t *testing.T) {
+	g := twittership.NewGame()
+	if g.Turn() != twittership.PlayerSide {
+		t.Fatalf("expected the player to place ships first")
+	}
+
+	err := g.LoadPlayerShips("A1H;B8V;E3H;G3V;H8H")
+	if err != nil {
+		t.Fatalf("load player ships: %v", err)
+	}
+
+	if g.Turn() != twittership.EnemySide {
+		t.Fatalf("expected the enemy to place ships after the player")
+	}
+
+	err = g.LoadEnemyShips("A1H;B8V;E3H;G3V;H8H")
+	if err != nil {
+		t.Fatalf("load enemy ships: %v", err)
+	}
+
+	if g.Turn() != twittership.PlayerSide {
+		t.Fatalf("expected the player to fire first")
+	}
+
+	_, err = g.PlayerVolley("J10")
+	if err != nil {
+		t.Fatalf("player volley: %v", err)
+	}
+
+	if g.Turn() != twittership.EnemySide {
+		t.Fatalf("expected the enemy to fire after the player")
+	}
+
+	err = g.Forfeit(twittership.EnemySide, twittership.Forfeited)
+	if err != nil {
+		t.Fatalf("forfeit: %v", err)
+	}
+
+	winner, _ := g.Winner()
+	if winner != twittership.PlayerSide {
+		t.Fatalf("expected the player to win after the enemy forfeited")
+	}
+}
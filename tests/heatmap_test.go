@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"testing"
+	"twittership"
+)
+
+func TestShotFrequencyCountsShotsAcrossGames(t *testing.T) {
+	t.Parallel()
+
+	games := []twittership.Game{newFleetTestGame(t), newFleetTestGame(t)}
+
+	player := twittership.ShotFrequency(games, twittership.PlayerSide)
+	// H8 was fired at by the player in both games and A1 only by the enemy
+	if player[7][7] != 2 || player[0][0] != 0 {
+		t.Fatalf("unexpected player shot counts %v at H8 and %v at A1", player[7][7], player[0][0])
+	}
+
+	enemy := twittership.ShotFrequency(games, twittership.EnemySide)
+	if enemy[0][0] != 2 || enemy.Max() != 2 {
+		t.Fatalf("unexpected enemy shot count %v at A1", enemy[0][0])
+	}
+}
+
+func TestProbabilityDensityUsesTheObservedShots(t *testing.T) {
+	t.Parallel()
+
+	empty := twittership.NewGame()
+	density := empty.ProbabilityDensity(twittership.PlayerSide)
+	if density[0][0] >= density[4][4] {
+		t.Fatalf("expected the center of an empty board to be more likely than a corner")
+	}
+
+	// The player hit the enemy battleship at B8 and missed at J1
+	density = newFleetTestGame(t).ProbabilityDensity(twittership.PlayerSide)
+	if density[1][7] != 0 || density[9][0] != 0 {
+		t.Fatalf("expected tiles that have been fired at to score zero")
+	}
+
+	if density[2][7] <= density[5][1] {
+		t.Fatalf("expected a tile next to a hit to be more likely than a tile far from it")
+	}
+}
+
+func TestGameImageCanDrawAHeatmap(t *testing.T) {
+	t.Parallel()
+
+	var values twittership.Heatmap
+	values[0][0] = 10
+
+	gameImage, err := twittership.NewGameImageWithOptions(twittership.NewGame(), 401, 401, twittership.ImageOptions{
+		Heatmap: &twittership.HeatmapOverlay{Values: values, Board: twittership.EnemySide, Title: "Shots"},
+	})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	img := gameImage.GetFullImage()
+	if img.Bounds().Dx() != 882 || img.Bounds().Dy() <= 491 {
+		t.Fatalf("expected the legend to be drawn below the boards but the image is %s", img.Bounds().Size())
+	}
+
+	// A1 on the enemy board is tinted and A2 is still water
+	if c := img.RGBAAt(483, 92); c == twittership.ClassicTheme.Water {
+		t.Errorf("expected the tile with the highest value to be tinted")
+	}
+
+	if c := img.RGBAAt(523, 92); c != twittership.ClassicTheme.Water {
+		t.Errorf("expected a tile with no value to be water but got %v", c)
+	}
+
+	// The legend bar ends with the strongest tint
+	found := false
+	for y := 491; y < img.Bounds().Dy() && !found; y++ {
+		for x := 0; x < img.Bounds().Dx() && !found; x++ {
+			found = img.RGBAAt(x, y) == img.RGBAAt(483, 92)
+		}
+	}
+
+	if !found {
+		t.Errorf("expected the legend to include the tint of the highest value")
+	}
+}
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"testing"
+	"twittership"
+)
+
+// newBenchmarkGame creates a game with enough volleys that most tiles drawn are not empty.
+func newBenchmarkGame(b *testing.B) twittership.Game {
+	return newTestGame(b, "A1;B1;C1;D1;E1;F1;G1;H8;H9;J10", "A2;B2;C2;D2;E3;E4;E5;J1;J2;J3")
+}
+
+func BenchmarkNewGameImageFromGame(b *testing.B) {
+	game := newBenchmarkGame(b)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := twittership.NewGameImageFromGame(game, 401, 401, "../game_template.png")
+		if err != nil {
+			b.Fatalf("creating game image: %v", err)
+		}
+	}
+}
+
+func BenchmarkNewGameImageWithOptions(b *testing.B) {
+	game := newBenchmarkGame(b)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := twittership.NewGameImageWithOptions(game, 401, 401, twittership.ImageOptions{Theme: twittership.DarkTheme})
+		if err != nil {
+			b.Fatalf("creating game image: %v", err)
+		}
+	}
+}
+
+func BenchmarkGameImageApplyShot(b *testing.B) {
+	game := newBenchmarkGame(b)
+
+	gameImage, err := twittership.NewGameImageWithOptions(game, 401, 401, twittership.ImageOptions{Theme: twittership.DarkTheme})
+	if err != nil {
+		b.Fatalf("creating game image: %v", err)
+	}
+
+	m, _ := game.LastMove()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err = gameImage.ApplyShot(game, m)
+		if err != nil {
+			b.Fatalf("applying shot: %v", err)
+		}
+	}
+}
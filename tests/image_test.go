@@ -1,26 +1,18 @@
 package tests
 
 import (
+	"bytes"
+	"errors"
 	"image"
+	"image/color"
+	"image/png"
 	"os"
+	"strings"
 	"testing"
 	"twittership"
+	"twittership/tests/imagetest"
 )
 
-func readTestFile(t *testing.T, filename string) image.Image {
-	f, err := os.Open("./assets/" + filename)
-	if err != nil {
-		t.Errorf("opening file %s: %v", filename, err)
-	}
-
-	testFile, _, err := image.Decode(f)
-	if err != nil {
-		t.Errorf("decoding file: %v", err)
-	}
-
-	return testFile
-}
-
 var drawImageParams = []struct {
 	name            string
 	playerPositions string
@@ -40,8 +32,8 @@ var drawImageParams = []struct {
 }
 
 // TestGameImageCanDrawPixelPerfectImages generates an image and makes sure that it will generate the same image
-// compared pixel by pixel. NOTE: If modifying the expected image make sure to save it without
-// an alpha channel otherwise it will generate a 32bit PNG rather than a 24bit PNG, as is expected.
+// compared pixel by pixel. Run the tests with -update to regenerate the expected images, they are written
+// without an alpha channel as 24bit PNGs.
 func TestGameImageCanDrawPixelPerfectImages(t *testing.T) {
 	t.Parallel()
 
@@ -74,15 +66,477 @@ func TestGameImageCanDrawPixelPerfectImages(t *testing.T) {
 				t.Fatalf("creating new game image: %v", err)
 			}
 
-			expectedImage := readTestFile(t, drawImageParam.expectedImage)
+			imagetest.AssertGolden(t, "assets/"+drawImageParam.expectedImage, gameImage.GetFullImage(), imagetest.Options{})
+		})
+	}
+}
+
+// newTestGame creates a game where both sides placed their ships at A1H;B8V;E3H;G3V;H8H
+// and fired the volleys provided, each a list of positions separated by a ;. The volleys
+// are fired in turn starting with the player, as they would be in a real game.
+func newTestGame(t testing.TB, playerVolleys, enemyVolleys string) twittership.Game {
+	t.Helper()
+
+	game := twittership.NewGame()
+	err := game.LoadPlayerShips("A1H;B8V;E3H;G3V;H8H")
+	if err != nil {
+		t.Fatalf("setting player ships: %v", err)
+	}
+
+	err = game.LoadEnemyShips("A1H;B8V;E3H;G3V;H8H")
+	if err != nil {
+		t.Fatalf("setting enemy ships: %v", err)
+	}
+
+	var volleys [2][]string
+	for i, positions := range []string{playerVolleys, enemyVolleys} {
+		if positions != "" {
+			volleys[i] = strings.Split(positions, ";")
+		}
+	}
+
+	for i := 0; i < len(volleys[0]) || i < len(volleys[1]); i++ {
+		if i < len(volleys[0]) {
+			_, err = game.PlayerVolley(volleys[0][i])
+			if err != nil {
+				t.Fatalf("firing player volley %s: %v", volleys[0][i], err)
+			}
+		}
+
+		if i < len(volleys[1]) {
+			_, err = game.EnemyVolley(volleys[1][i])
+			if err != nil {
+				t.Fatalf("firing enemy volley %s: %v", volleys[1][i], err)
+			}
+		}
+	}
+
+	return game
+}
+
+func newImageTestGame(t *testing.T) twittership.Game {
+	return newTestGame(t, "A1;B1", "A2;B2")
+}
+
+func TestGameImageUsesTheEmbeddedTemplateWhenNoPathIsGiven(t *testing.T) {
+	t.Parallel()
+
+	gameImage, err := twittership.NewGameImageFromGame(newImageTestGame(t), 401, 401, "")
+	if err != nil {
+		t.Fatalf("creating new game image: %v", err)
+	}
+
+	imagetest.AssertGolden(t, "assets/game_1.png", gameImage.GetFullImage(), imagetest.Options{})
+}
+
+func TestGameImageCanReadTheTemplateFromAReader(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("../game_template.png")
+	if err != nil {
+		t.Fatalf("opening template: %v", err)
+	}
+	defer f.Close()
+
+	gameImage, err := twittership.NewGameImageFromReader(newImageTestGame(t), 401, 401, f)
+	if err != nil {
+		t.Fatalf("creating new game image: %v", err)
+	}
+
+	imagetest.AssertGolden(t, "assets/game_1.png", gameImage.GetFullImage(), imagetest.Options{})
+}
+
+func TestGameImageDrawsTheFrameWithoutATemplate(t *testing.T) {
+	t.Parallel()
+
+	gameImage, err := twittership.NewGameImageFromTemplate(newImageTestGame(t), 251, 251, nil)
+	if err != nil {
+		t.Fatalf("creating new game image: %v", err)
+	}
+
+	img := gameImage.GetFullImage()
+	if img.Bounds() != image.Rect(0, 0, 582, 341) {
+		t.Fatalf("unexpected image bounds %s", img.Bounds())
+	}
+
+	// The header, the line under the header, and the tile for the player hit at A2
+	expectedColors := []struct {
+		x, y  int
+		color color.RGBA
+	}{
+		{5, 5, color.RGBA{R: 209, G: 225, B: 249, A: 255}},
+		{5, 50, color.RGBA{A: 255}},
+		{67, 102, color.RGBA{R: 255, G: 54, B: 51, A: 255}},
+	}
+
+	for _, expected := range expectedColors {
+		if img.RGBAAt(expected.x, expected.y) != expected.color {
+			t.Fatalf("expected %v at x: %d y: %d but got %v", expected.color, expected.x, expected.y, img.RGBAAt(expected.x, expected.y))
+		}
+	}
+}
+
+func TestGameImageClassicThemeMatchesTheOriginalImage(t *testing.T) {
+	t.Parallel()
+
+	template, err := twittership.DefaultTemplate()
+	if err != nil {
+		t.Fatalf("loading default template: %v", err)
+	}
+
+	gameImage, err := twittership.NewGameImageWithOptions(newImageTestGame(t), 401, 401, twittership.ImageOptions{
+		Template: template,
+		Theme:    twittership.ClassicTheme,
+	})
+	if err != nil {
+		t.Fatalf("creating new game image: %v", err)
+	}
+
+	imagetest.AssertGolden(t, "assets/game_1.png", gameImage.GetFullImage(), imagetest.Options{})
+}
+
+func TestGameImageDrawsEveryBuiltInThemesColors(t *testing.T) {
+	t.Parallel()
+
+	for _, theme := range twittership.Themes() {
+		theme := theme
+		t.Run(theme.Name, func(t *testing.T) {
+			found, ok := twittership.ThemeByName(theme.Name)
+			if !ok || found.Name != theme.Name {
+				t.Fatalf("theme %s could not be found by name", theme.Name)
+			}
+
+			gameImage, err := twittership.NewGameImageWithOptions(newImageTestGame(t), 401, 401, twittership.ImageOptions{Theme: theme})
+			if err != nil {
+				t.Fatalf("creating new game image: %v", err)
+			}
+
+			img := gameImage.GetFullImage()
+
+			// Header, water at J10 on the enemy board, the ship at A1 on the player board,
+			// the hit at A2 on the player board, and the miss at B1 on the enemy board
+			expectedColors := []struct {
+				x, y  int
+				color color.RGBA
+			}{
+				{5, 5, theme.Header},
+				{860, 470, theme.Water},
+				{42, 92, theme.Ship},
+				{82, 92, theme.Hit},
+				{483, 132, theme.Miss},
+			}
+
+			for _, expected := range expectedColors {
+				if img.RGBAAt(expected.x, expected.y) != expected.color {
+					t.Fatalf("expected %v at x: %d y: %d but got %v", expected.color, expected.x, expected.y, img.RGBAAt(expected.x, expected.y))
+				}
+			}
+		})
+	}
+}
+
+func countTileColor(img image.Image, tileX, tileY int, c color.RGBA) int {
+	count := 0
+	for x := 41 + tileX*40; x < 80+tileX*40; x++ {
+		for y := 91 + tileY*40; y < 130+tileY*40; y++ {
+			if color.RGBAModel.Convert(img.At(x, y)) == c {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+func TestGameImageDrawsShipsFromSprites(t *testing.T) {
+	game := newImageTestGame(t)
+
+	gameImage, err := twittership.NewGameImageWithOptions(game, 401, 401, twittership.ImageOptions{
+		Sprites: twittership.DefaultSpriteSheet(twittership.ClassicTheme),
+	})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	img := gameImage.GetFullImage()
+
+	// A3 is an undamaged part of the aircraft carrier
+	if countTileColor(img, 2, 0, twittership.ClassicTheme.Ship) == 0 {
+		t.Errorf("expected the undamaged sprite to use the ship color")
+	}
+
+	if countTileColor(img, 2, 0, twittership.ClassicTheme.Water) == 0 {
+		t.Errorf("expected the water to be visible around the sprite")
+	}
+
+	// A2 has been hit so the damaged sprite is drawn instead of filling the tile
+	if countTileColor(img, 1, 0, twittership.ClassicTheme.Hit) != 0 {
+		t.Errorf("expected the damaged sprite to be drawn instead of the hit color")
+	}
+
+	if countTileColor(img, 1, 0, twittership.ClassicTheme.Glyph) == 0 {
+		t.Errorf("expected the hit glyph to be drawn on top of the damaged sprite")
+	}
+
+	// B2 is a miss and is drawn the same as without sprites
+	if countTileColor(img, 1, 1, twittership.ClassicTheme.Miss) == 0 {
+		t.Errorf("expected misses to be drawn with the miss color")
+	}
+}
+
+func TestSpriteSheetsCanBeLoaded(t *testing.T) {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, twittership.DefaultSpriteSheetImage(twittership.DarkTheme))
+	if err != nil {
+		t.Fatalf("encoding sprite sheet: %v", err)
+	}
+
+	_, err = twittership.LoadSpriteSheet(&buf)
+	if err != nil {
+		t.Fatalf("expected the default sprite sheet to load but got: %v", err)
+	}
+
+	_, err = twittership.NewSpriteSheet(image.NewRGBA(image.Rect(0, 0, 100, 100)))
+	if err == nil {
+		t.Fatalf("expected an error for a sprite sheet that can't be split into sprites")
+	}
+
+	_, err = twittership.LoadSpriteSheet(strings.NewReader("not an image"))
+	if err == nil {
+		t.Fatalf("expected an error for a sprite sheet that isn't an image")
+	}
+}
+
+func TestGameImageShowsSunkShipsOnBothBoards(t *testing.T) {
+	game := newFleetTestGame(t)
+
+	gameImage, err := twittership.NewGameImageWithOptions(game, 401, 401, twittership.ImageOptions{})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	img := gameImage.GetFullImage()
+
+	// E3 is part of the sunk player submarine
+	if countTileColor(img, 2, 4, twittership.ClassicTheme.Sunk) == 0 {
+		t.Errorf("expected the sunk player ship to use the sunk color")
+	}
+
+	// A1 is a hit on the player aircraft carrier which hasn't sunk
+	if countTileColor(img, 0, 0, twittership.ClassicTheme.Sunk) != 0 {
+		t.Errorf("expected a damaged ship not to use the sunk color")
+	}
+
+	// H8 is part of the sunk enemy destroyer, which is 11 tiles to the right on the enemy board
+	if countTileColor(img, 18, 7, twittership.ClassicTheme.Sunk) == 0 {
+		t.Errorf("expected the sunk enemy ship to be revealed with the sunk color")
+	}
+
+	// B8 is a hit on the enemy battleship which hasn't sunk
+	if countTileColor(img, 18, 1, twittership.ClassicTheme.Sunk) != 0 {
+		t.Errorf("expected a damaged enemy ship not to use the sunk color")
+	}
+}
+
+func TestGameImageCanDrawTheFleetStatus(t *testing.T) {
+	game := newFleetTestGame(t)
+
+	gameImage, err := twittership.NewGameImageWithOptions(game, 401, 401, twittership.ImageOptions{FleetStatus: true})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	bounds := gameImage.GetFullImage().Bounds()
+	if bounds.Dx() != 882 || bounds.Dy() <= 491 {
+		t.Fatalf("expected the fleet status to be drawn below the boards but the image is %s", bounds.Size())
+	}
+
+	// The damaged aircraft carrier is listed in the hit color
+	found := false
+	for x := 0; x < 441 && !found; x++ {
+		for y := 491; y < bounds.Dy() && !found; y++ {
+			found = color.RGBAModel.Convert(gameImage.GetFullImage().At(x, y)) == twittership.ClassicTheme.Hit
+		}
+	}
+
+	if !found {
+		t.Errorf("expected damaged ships to be listed in the hit color")
+	}
+}
+
+func TestGameImageCanBeDrawnAtAHigherScale(t *testing.T) {
+	t.Parallel()
+
+	gameImage, err := twittership.NewGameImageWithOptions(newImageTestGame(t), 401, 401, twittership.ImageOptions{Scale: 2})
+	if err != nil {
+		t.Fatalf("creating new game image: %v", err)
+	}
+
+	img := gameImage.GetFullImage()
+	if img.Bounds() != image.Rect(0, 0, 1764, 982) {
+		t.Fatalf("unexpected image bounds %s", img.Bounds())
+	}
+
+	// The grid lines are two pixels wide on either side of the player hit at A2
+	expectedColors := []struct {
+		x, y  int
+		color color.RGBA
+	}{
+		{160, 200, twittership.ClassicTheme.Grid},
+		{161, 200, twittership.ClassicTheme.Grid},
+		{164, 184, twittership.ClassicTheme.Hit},
+		{238, 258, twittership.ClassicTheme.Hit},
+		{240, 200, twittership.ClassicTheme.Grid},
+		{241, 200, twittership.ClassicTheme.Grid},
+	}
+
+	for _, expected := range expectedColors {
+		if img.RGBAAt(expected.x, expected.y) != expected.color {
+			t.Fatalf("expected %v at x: %d y: %d but got %v", expected.color, expected.x, expected.y, img.RGBAAt(expected.x, expected.y))
+		}
+	}
+}
+
+func TestGameImageCanBeDrawnOnACanvasWithNonSquareTiles(t *testing.T) {
+	t.Parallel()
+
+	gameImage, err := twittership.NewGameImageWithOptions(newImageTestGame(t), 401, 401, twittership.ImageOptions{
+		CanvasWidth:  1200,
+		CanvasHeight: 675,
+	})
+	if err != nil {
+		t.Fatalf("creating new game image: %v", err)
+	}
+
+	img := gameImage.GetFullImage()
+	if img.Bounds() != image.Rect(0, 0, 1200, 675) {
+		t.Fatalf("unexpected image bounds %s", img.Bounds())
+	}
+
+	// The tiles are 55x58 and the boards are centered, so the player board starts at 49x92.
+	// The hit at A2 fills its whole tile and the miss at B2 is in the next row.
+	expectedColors := []struct {
+		x, y  int
+		color color.RGBA
+	}{
+		{105, 93, twittership.ClassicTheme.Hit},
+		{158, 149, twittership.ClassicTheme.Hit},
+		{158, 150, twittership.ClassicTheme.Grid},
+		{106, 152, twittership.ClassicTheme.Miss},
+		{158, 207, twittership.ClassicTheme.Miss},
+	}
+
+	for _, expected := range expectedColors {
+		if img.RGBAAt(expected.x, expected.y) != expected.color {
+			t.Fatalf("expected %v at x: %d y: %d but got %v", expected.color, expected.x, expected.y, img.RGBAAt(expected.x, expected.y))
+		}
+	}
+}
+
+func TestGameImageReturnsAnErrorWhenTheCanvasIsTooSmall(t *testing.T) {
+	t.Parallel()
+
+	_, err := twittership.NewGameImageWithOptions(newImageTestGame(t), 401, 401, twittership.ImageOptions{
+		CanvasWidth:  200,
+		CanvasHeight: 150,
+	})
+	if !errors.Is(err, twittership.ErrCanvasTooSmall) {
+		t.Fatalf("expected ErrCanvasTooSmall but got %v", err)
+	}
+}
+
+func TestGameImageDrawsWholeTilesForAnyBoardSize(t *testing.T) {
+	t.Parallel()
+
+	template, err := twittership.DefaultTemplate()
+	if err != nil {
+		t.Fatalf("loading default template: %v", err)
+	}
+
+	// The extra pixels can't fit another tile so the image is the same as a 401x401 board
+	gameImage, err := twittership.NewGameImageWithOptions(newImageTestGame(t), 405, 405, twittership.ImageOptions{Template: template})
+	if err != nil {
+		t.Fatalf("creating new game image: %v", err)
+	}
+
+	if gameImage.GetFullImage().Bounds() != image.Rect(0, 0, 882, 491) {
+		t.Fatalf("unexpected image bounds %s", gameImage.GetFullImage().Bounds())
+	}
+
+	imagetest.AssertGolden(t, "assets/game_1.png", gameImage.GetFullImage(), imagetest.Options{})
+}
+
+func TestGameImageApplyShotMatchesANewImage(t *testing.T) {
+	t.Parallel()
+
+	optionSets := map[string]twittership.ImageOptions{
+		"plain": {},
+		"annotated": {
+			Theme:              twittership.DarkTheme,
+			Sprites:            twittership.DefaultSpriteSheet(twittership.DarkTheme),
+			FleetStatus:        true,
+			HighlightLastMoves: true,
+			ShotNumbers:        true,
+			Caption:            true,
+		},
+		"heatmap": {Heatmap: &twittership.HeatmapOverlay{Title: "Shots"}},
+	}
+
+	for name, opts := range optionSets {
+		opts := opts
+		t.Run(name, func(t *testing.T) {
+			game := twittership.NewGame()
+			err := game.LoadPlayerShips("A1H;B8V;E3H;G3V;H8H")
+			if err != nil {
+				t.Fatalf("setting player ships: %v", err)
+			}
+
+			err = game.LoadEnemyShips("A1H;B8V;E3H;G3V;H8H")
+			if err != nil {
+				t.Fatalf("setting enemy ships: %v", err)
+			}
+
+			gameImage, err := twittership.NewGameImageWithOptions(game, 401, 401, opts)
+			if err != nil {
+				t.Fatalf("creating game image: %v", err)
+			}
+
+			for _, position := range []string{"H8", "A1", "H9", "A2", "J10", "E3", "B8", "E4", "C8", "E5"} {
+				for _, side := range []twittership.Side{twittership.PlayerSide, twittership.EnemySide} {
+					_, err = game.Volley(side, position)
+					if err != nil {
+						t.Fatalf("firing volley at %s: %v", position, err)
+					}
+
+					m, _ := game.LastMove()
+					err = gameImage.ApplyShot(game, m)
+					if err != nil {
+						t.Fatalf("applying shot %s: %v", position, err)
+					}
 
-			for x := 0; x < 882; x++ {
-				for y := 0; y < 491; y++ {
-					if expectedImage.At(x, y) != gameImage.GetFullImage().At(x, y) {
-						t.Fatalf("Color at x: %d y: %d did not match the expected image color", x, y)
+					expected, err := twittership.NewGameImageWithOptions(game, 401, 401, opts)
+					if err != nil {
+						t.Fatalf("creating game image: %v", err)
 					}
+
+					imagetest.AssertSame(t, expected.GetFullImage(), gameImage.GetFullImage(), imagetest.Options{})
 				}
 			}
 		})
 	}
 }
+
+func TestGameImageApplyShotRejectsMovesFromAnotherGame(t *testing.T) {
+	t.Parallel()
+
+	game := newImageTestGame(t)
+	gameImage, err := twittership.NewGameImageWithOptions(game, 401, 401, twittership.ImageOptions{})
+	if err != nil {
+		t.Fatalf("creating game image: %v", err)
+	}
+
+	err = gameImage.ApplyShot(game, twittership.Move{Number: 1, Position: "J10", X: 9, Y: 9})
+	if !errors.Is(err, twittership.ErrShotNotInGame) {
+		t.Fatalf("expected ErrShotNotInGame but got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+// Package imagetest compares images in tests. Images can be compared with a tolerance so small
+// changes such as anti-aliasing don't break golden tests, and a diff image showing every pixel
+// that didn't match is written whenever a comparison fails.
+//
+// Running the tests with -update writes the actual images over the golden images instead of
+// comparing them:
+//
+//	go test ./tests -update
+package imagetest
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "write the actual images over the golden images instead of comparing them")
+
+// diffColor marks the pixels that didn't match in a diff image.
+var diffColor = color.RGBA{R: 255, G: 0, B: 255, A: 255}
+
+// Options configures how images are compared.
+type Options struct {
+	// Tolerance is the largest difference allowed in any color channel of a pixel, from 0 to
+	// 255. The zero value only allows identical pixels.
+	Tolerance uint8
+	// MaxDiffPixels is how many pixels may differ by more than the tolerance.
+	MaxDiffPixels int
+	// DiffDir is where diff images are written when a comparison fails. It defaults to a
+	// directory named imagetest in the system temporary directory.
+	DiffDir string
+}
+
+// Result describes how two images differ.
+type Result struct {
+	// SizeMismatch is true when the images have different bounds, no pixels are compared.
+	SizeMismatch bool
+	// DiffPixels is the number of pixels that differ by more than the tolerance.
+	DiffPixels int
+	// MaxDelta is the largest difference in any color channel of any pixel.
+	MaxDelta uint8
+	// Diff is the actual image faded out with every pixel that differs by more than the
+	// tolerance drawn in magenta. It is nil when the sizes don't match.
+	Diff *image.RGBA
+}
+
+// Compare compares every pixel of actual to expected.
+func Compare(expected, actual image.Image, opts Options) Result {
+	if expected.Bounds().Size() != actual.Bounds().Size() {
+		return Result{SizeMismatch: true}
+	}
+
+	size := expected.Bounds().Size()
+	result := Result{Diff: image.NewRGBA(image.Rect(0, 0, size.X, size.Y))}
+	draw.Draw(result.Diff, result.Diff.Rect, actual, actual.Bounds().Min, draw.Src)
+	draw.Draw(result.Diff, result.Diff.Rect, image.NewUniform(color.RGBA{R: 255, G: 255, B: 255, A: 192}), image.Point{}, draw.Over)
+
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			e := color.RGBAModel.Convert(expected.At(expected.Bounds().Min.X+x, expected.Bounds().Min.Y+y)).(color.RGBA)
+			a := color.RGBAModel.Convert(actual.At(actual.Bounds().Min.X+x, actual.Bounds().Min.Y+y)).(color.RGBA)
+
+			delta := maxDelta(e, a)
+			if delta > result.MaxDelta {
+				result.MaxDelta = delta
+			}
+
+			if delta > opts.Tolerance {
+				result.DiffPixels++
+				result.Diff.SetRGBA(x, y, diffColor)
+			}
+		}
+	}
+
+	return result
+}
+
+// Matches reports whether the result is within the options it was compared with.
+func (r Result) Matches(opts Options) bool {
+	return !r.SizeMismatch && r.DiffPixels <= opts.MaxDiffPixels
+}
+
+// AssertSame fails the test when actual doesn't match expected, writing a diff image.
+func AssertSame(t testing.TB, expected, actual image.Image, opts Options) {
+	t.Helper()
+
+	result := Compare(expected, actual, opts)
+	if result.SizeMismatch {
+		t.Fatalf("expected image bounds %s but got %s", expected.Bounds(), actual.Bounds())
+	}
+
+	if result.Matches(opts) {
+		return
+	}
+
+	diff, err := writeDiff(t, result.Diff, opts)
+	if err != nil {
+		t.Fatalf("%d pixels differ by up to %d, unable to write the diff image: %v", result.DiffPixels, result.MaxDelta, err)
+	}
+
+	t.Fatalf("%d pixels differ by up to %d, the mismatched pixels are shown in %s", result.DiffPixels, result.MaxDelta, diff)
+}
+
+// AssertGolden fails the test when actual doesn't match the PNG at path, writing a diff
+// image. With -update actual is written to path instead.
+func AssertGolden(t testing.TB, path string, actual image.Image, opts Options) {
+	t.Helper()
+
+	if *update {
+		err := writePNG(path, actual)
+		if err != nil {
+			t.Fatalf("updating golden image %s: %v", path, err)
+		}
+
+		return
+	}
+
+	AssertSame(t, Load(t, path), actual, opts)
+}
+
+// Load decodes the image at path.
+func Load(t testing.TB, path string) image.Image {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening image %s: %v", path, err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		t.Fatalf("decoding image %s: %v", path, err)
+	}
+
+	return img
+}
+
+func writeDiff(t testing.TB, diff image.Image, opts Options) (string, error) {
+	dir := opts.DiffDir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "imagetest")
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	// Subtests have slashes in their names
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	path := filepath.Join(dir, name+".diff.png")
+
+	return path, writePNG(path, diff)
+}
+
+// writePNG writes img to path. Opaque images are written without an alpha channel. The image
+// is written to a temporary file first as several tests may update the same golden image.
+func writePNG(path string, img image.Image) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	err = png.Encode(f, img)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func maxDelta(a, b color.RGBA) uint8 {
+	delta := uint8(0)
+	for _, pair := range [][2]uint8{{a.R, b.R}, {a.G, b.G}, {a.B, b.B}, {a.A, b.A}} {
+		d := pair[0] - pair[1]
+		if pair[1] > pair[0] {
+			d = pair[1] - pair[0]
+		}
+
+		if d > delta {
+			delta = d
+		}
+	}
+
+	return delta
+}
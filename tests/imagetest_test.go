@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"twittership/tests/imagetest"
+)
+
+func newSolidImage(size int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+
+	return img
+}
+
+func TestImageCompareAllowsDifferencesWithinTheTolerance(t *testing.T) {
+	t.Parallel()
+
+	expected := newSolidImage(10, color.RGBA{R: 100, G: 100, B: 100, A: 255})
+	actual := newSolidImage(10, color.RGBA{R: 100, G: 100, B: 100, A: 255})
+	actual.SetRGBA(2, 3, color.RGBA{R: 104, G: 100, B: 98, A: 255})
+
+	exact := imagetest.Compare(expected, actual, imagetest.Options{})
+	if exact.Matches(imagetest.Options{}) || exact.DiffPixels != 1 || exact.MaxDelta != 4 {
+		t.Fatalf("expected one pixel to differ by 4 but got %d pixels differing by %d", exact.DiffPixels, exact.MaxDelta)
+	}
+
+	opts := imagetest.Options{Tolerance: 4}
+	if result := imagetest.Compare(expected, actual, opts); !result.Matches(opts) {
+		t.Fatalf("expected the images to match within a tolerance of 4")
+	}
+
+	opts = imagetest.Options{MaxDiffPixels: 1}
+	if result := imagetest.Compare(expected, actual, opts); !result.Matches(opts) {
+		t.Fatalf("expected the images to match when one pixel may differ")
+	}
+}
+
+func TestImageCompareMarksMismatchedPixelsInTheDiff(t *testing.T) {
+	t.Parallel()
+
+	expected := newSolidImage(10, color.RGBA{A: 255})
+	actual := newSolidImage(10, color.RGBA{A: 255})
+	actual.SetRGBA(5, 5, color.RGBA{R: 255, A: 255})
+
+	result := imagetest.Compare(expected, actual, imagetest.Options{})
+	if result.Diff == nil || result.Diff.Bounds() != expected.Bounds() {
+		t.Fatalf("expected a diff image the same size as the images")
+	}
+
+	marked := result.Diff.RGBAAt(5, 5)
+	if marked == result.Diff.RGBAAt(0, 0) {
+		t.Fatalf("expected the mismatched pixel to stand out in the diff")
+	}
+
+	for _, p := range []image.Point{{0, 0}, {9, 9}, {4, 5}} {
+		if result.Diff.RGBAAt(p.X, p.Y) == marked {
+			t.Fatalf("expected the matching pixel at %s not to be marked", p)
+		}
+	}
+}
+
+func TestImageCompareReportsDifferentSizes(t *testing.T) {
+	t.Parallel()
+
+	result := imagetest.Compare(newSolidImage(10, color.RGBA{}), newSolidImage(11, color.RGBA{}), imagetest.Options{MaxDiffPixels: 1000})
+	if !result.SizeMismatch || result.Matches(imagetest.Options{MaxDiffPixels: 1000}) {
+		t.Fatalf("expected images with different sizes never to match")
+	}
+}
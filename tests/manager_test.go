@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"twittership"
+)
+
+func TestManagerRejectsDuplicateAndUnknownGames(t *testing.T) {
+	t.Parallel()
+
+	m := twittership.NewManager()
+	err := m.Create("game-1")
+	if err != nil {
+		t.Fatalf("creating game: %v", err)
+	}
+
+	err = m.Create("game-1")
+	if !errors.Is(err, twittership.ErrGameExists) {
+		t.Fatalf("expected ErrGameExists but got %v", err)
+	}
+
+	_, err = m.PlayerVolley("game-2", "A1")
+	if !errors.Is(err, twittership.ErrGameNotFound) {
+		t.Fatalf("expected ErrGameNotFound but got %v", err)
+	}
+
+	err = m.Remove("game-1")
+	if err != nil {
+		t.Fatalf("removing game: %v", err)
+	}
+
+	if m.Len() != 0 {
+		t.Fatalf("expected manager to be empty but it has %d games", m.Len())
+	}
+}
+
+func TestManagerRunsDifferentGamesInParallel(t *testing.T) {
+	t.Parallel()
+
+	m := twittership.NewManager()
+	for _, id := range []string{"slow", "fast"} {
+		err := m.Create(id)
+		if err != nil {
+			t.Fatalf("creating game %s: %v", id, err)
+		}
+	}
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_ = m.Do("slow", func(g *twittership.Game) error {
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+
+	<-locked
+	defer close(release)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- m.Do("fast", func(g *twittership.Game) error {
+			return g.LoadPlayerShips("A1H;B8V;E3H;G3V;H8H")
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("loading ships: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("operation on one game was blocked by an operation on another game")
+	}
+}
+
+// TestManagerSerialisesThousandsOfSimultaneousMoves fires every possible volley for both
+// sides of many games at the same time, except for the last destroyer tile so neither
+// side wins. Run with -race to prove the manager is race free.
+func TestManagerSerialisesThousandsOfSimultaneousMoves(t *testing.T) {
+	t.Parallel()
+
+	const games = 40
+	m := twittership.NewManager()
+
+	for i := 0; i < games; i++ {
+		id := fmt.Sprintf("game-%d", i)
+		err := m.Create(id)
+		if err != nil {
+			t.Fatalf("creating game %s: %v", id, err)
+		}
+
+		err = m.Do(id, func(g *twittership.Game) error {
+			err := g.LoadPlayerShips("A1H;B8V;E3H;G3V;H8H")
+			if err != nil {
+				return err
+			}
+
+			return g.LoadEnemyShips("A1H;B8V;E3H;G3V;H8H")
+		})
+		if err != nil {
+			t.Fatalf("loading ships for %s: %v", id, err)
+		}
+	}
+
+	start := make(chan struct{})
+	errs := make(chan error, games*300)
+	var wg sync.WaitGroup
+
+	for i := 0; i < games; i++ {
+		id := fmt.Sprintf("game-%d", i)
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 10; x++ {
+				position := fmt.Sprintf("%c%d", 'A'+y, x+1)
+				if position == "H9" {
+					continue
+				}
+
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					<-start
+					_, err := m.PlayerVolley(id, position)
+					if err != nil {
+						errs <- err
+					}
+				}()
+
+				go func() {
+					defer wg.Done()
+					<-start
+					_, err := m.EnemyVolley(id, position)
+					if err != nil {
+						errs <- err
+					}
+
+					// Snapshots are taken while the game is still being played
+					_, err = m.Get(id)
+					if err != nil {
+						errs <- err
+					}
+				}()
+			}
+		}
+	}
+
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("simultaneous move: %v", err)
+	}
+
+	for _, id := range m.IDs() {
+		g, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("getting game %s: %v", id, err)
+		}
+
+		volleyMap := g.GetVolleyMap()
+		for side := range volleyMap {
+			for y := range volleyMap[side] {
+				for x := range volleyMap[side][y] {
+					if volleyMap[side][y][x] == -1 && (y != 7 || x != 8) {
+						t.Fatalf("game %s board %d is missing the volley at x: %d y: %d", id, side, x, y)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"twittership"
+)
+
+func newTestMatchmaker(t *testing.T, ratings map[string]float64, notices *[]twittership.MatchNotice) (*twittership.Matchmaker, *twittership.Manager, *twittership.Stats, *fakeClock) {
+	store := newRatedStatsStore(t, ratings)
+
+	stats := twittership.NewStats(store)
+	m := twittership.NewManager()
+	m.SetStats(stats)
+
+	clock := &fakeClock{now: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)}
+	mm := twittership.NewMatchmaker(m, twittership.MatchmakerOptions{
+		InitialWindow:  100,
+		WindowGrowth:   100,
+		GrowthInterval: time.Minute,
+		MaxWindow:      400,
+	}, func(notice twittership.MatchNotice) {
+		*notices = append(*notices, notice)
+	})
+	mm.SetClock(clock)
+
+	return mm, m, stats, clock
+}
+
+func joinQueue(t *testing.T, mm *twittership.Matchmaker, requests ...twittership.MatchRequest) {
+	t.Helper()
+
+	for _, req := range requests {
+		err := mm.Join(req)
+		if err != nil {
+			t.Fatalf("joining %s: %v", req.Handle, err)
+		}
+	}
+}
+
+func TestMatchmakerPairsTheClosestRatingsAndCreatesTheGame(t *testing.T) {
+	t.Parallel()
+
+	var notices []twittership.MatchNotice
+	mm, m, _, _ := newTestMatchmaker(t, map[string]float64{"alice": 1500, "bob": 1900, "carol": 1540, "dave": 1580}, &notices)
+
+	joinQueue(t, mm,
+		twittership.MatchRequest{Handle: "alice", Rated: true},
+		twittership.MatchRequest{Handle: "bob", Rated: true},
+		twittership.MatchRequest{Handle: "dave", Rated: true},
+		twittership.MatchRequest{Handle: "carol", Rated: true},
+	)
+
+	matches, err := mm.Match()
+	if err != nil {
+		t.Fatalf("matching: %v", err)
+	}
+
+	// Alice waited longest and carol is closer to her than dave
+	if len(matches) != 1 || matches[0].Players != (twittership.Players{"alice", "carol"}) {
+		t.Fatalf("expected alice to be matched with carol but got %+v", matches)
+	}
+
+	players, err := m.Players(matches[0].GameID)
+	if err != nil {
+		t.Fatalf("loading the game: %v", err)
+	}
+
+	if players != matches[0].Players {
+		t.Errorf("expected the game to be created with the players but got %v", players)
+	}
+
+	if len(notices) != 2 || notices[0].Handle != "alice" || notices[0].Opponent != "carol" || notices[1].Side != twittership.EnemySide {
+		t.Errorf("expected both players to be notified but got %+v", notices)
+	}
+
+	if queued := mm.Queued(); len(queued) != 2 || queued[0] != "bob" || queued[1] != "dave" {
+		t.Errorf("expected bob and dave to still be queued but got %v", queued)
+	}
+}
+
+func TestMatchmakerNoticesCarryTheTokenOfEachSide(t *testing.T) {
+	t.Parallel()
+
+	var notices []twittership.MatchNotice
+	mm, m, _, _ := newTestMatchmaker(t, map[string]float64{"alice": 1500, "carol": 1540}, &notices)
+
+	server := httptest.NewServer(twittership.NewServer(m, "../game_template.png"))
+	defer server.Close()
+
+	joinQueue(t, mm,
+		twittership.MatchRequest{Handle: "alice", Rated: true},
+		twittership.MatchRequest{Handle: "carol", Rated: true},
+	)
+
+	matches, err := mm.Match()
+	if err != nil {
+		t.Fatalf("matching: %v", err)
+	}
+
+	if len(matches) != 1 || len(notices) != 2 {
+		t.Fatalf("expected one match with two notices but got %+v and %+v", matches, notices)
+	}
+
+	id := matches[0].GameID
+	if notices[0].Token == "" || notices[0].Token == notices[1].Token {
+		t.Fatalf("expected a different token for each side but got %+v", notices)
+	}
+
+	for _, notice := range notices {
+		res, body := doRequest(t, server, http.MethodPost, "/games/"+id+"/ships", notice.Token, `{"positions": "A1H;B8V;E3H;G3V;H8H"}`)
+		expectStatus(t, res, body, http.StatusOK)
+	}
+
+	spectator := dialLive(t, server.URL, id, "")
+	defer spectator.Close()
+
+	// Only the token of the player side may fire first
+	res, body := doRequest(t, server, http.MethodPost, "/games/"+id+"/volleys", notices[1].Token, `{"position": "H8"}`)
+	expectStatus(t, res, body, http.StatusConflict)
+
+	res, body = doRequest(t, server, http.MethodPost, "/games/"+id+"/volleys", notices[0].Token, `{"position": "H8"}`)
+	expectStatus(t, res, body, http.StatusOK)
+
+	shot := readLiveEvent(t, spectator)
+	if shot.Type != "shot" || shot.Move == nil || shot.Move.Position != "H8" {
+		t.Errorf("expected spectators of the match to receive the shot but got %+v", shot)
+	}
+}
+
+func TestMatchmakerWidensTheWindowTheLongerPlayersWait(t *testing.T) {
+	t.Parallel()
+
+	var notices []twittership.MatchNotice
+	mm, _, _, clock := newTestMatchmaker(t, map[string]float64{"alice": 1500, "bob": 1750}, &notices)
+
+	joinQueue(t, mm, twittership.MatchRequest{Handle: "alice"}, twittership.MatchRequest{Handle: "bob"})
+
+	for _, wait := range []time.Duration{0, time.Minute} {
+		clock.Advance(wait)
+
+		matches, err := mm.Match()
+		if err != nil {
+			t.Fatalf("matching: %v", err)
+		}
+
+		if len(matches) != 0 {
+			t.Fatalf("expected players 250 apart not to be matched after %s but got %+v", wait, matches)
+		}
+	}
+
+	clock.Advance(time.Minute)
+
+	matches, err := mm.Match()
+	if err != nil {
+		t.Fatalf("matching: %v", err)
+	}
+
+	if len(matches) != 1 {
+		t.Fatalf("expected the players to be matched once the window passed 250 but got %+v", matches)
+	}
+}
+
+func TestMatchmakerUsesTheRatingFromGamesFinishedWhileQueued(t *testing.T) {
+	t.Parallel()
+
+	var notices []twittership.MatchNotice
+	mm, _, stats, _ := newTestMatchmaker(t, map[string]float64{"alice": 1500, "bob": 1620, "carol": 1200}, &notices)
+
+	joinQueue(t, mm, twittership.MatchRequest{Handle: "alice"}, twittership.MatchRequest{Handle: "bob"})
+
+	matches, err := mm.Match()
+	if err != nil {
+		t.Fatalf("matching: %v", err)
+	}
+
+	if len(matches) != 0 {
+		t.Fatalf("expected players 120 apart not to be matched but got %+v", matches)
+	}
+
+	// Bob loses a rated game to a much lower rated player while still queued
+	g := twittership.NewGame()
+	err = g.Forfeit(twittership.PlayerSide, twittership.Forfeited)
+	if err != nil {
+		t.Fatalf("forfeiting game: %v", err)
+	}
+
+	err = stats.RecordGame(twittership.Players{"bob", "carol"}, g)
+	if err != nil {
+		t.Fatalf("recording game: %v", err)
+	}
+
+	bob, err := stats.Player("bob")
+	if err != nil {
+		t.Fatalf("loading bob: %v", err)
+	}
+
+	if bob.Rating.Rating >= 1600 {
+		t.Fatalf("expected bob to drop within 100 of alice but got %v", bob.Rating.Rating)
+	}
+
+	matches, err = mm.Match()
+	if err != nil {
+		t.Fatalf("matching: %v", err)
+	}
+
+	if len(matches) != 1 || matches[0].Players != (twittership.Players{"alice", "bob"}) {
+		t.Errorf("expected alice and bob to be matched on bob's new rating but got %+v", matches)
+	}
+}
+
+func TestMatchmakerOnlyMatchesCompatibleRequests(t *testing.T) {
+	t.Parallel()
+
+	var notices []twittership.MatchNotice
+	mm, m, stats, _ := newTestMatchmaker(t, nil, &notices)
+
+	joinQueue(t, mm,
+		twittership.MatchRequest{Handle: "alice", Rated: true},
+		twittership.MatchRequest{Handle: "bob", Rated: false},
+		twittership.MatchRequest{Handle: "carol", Rated: false, BoardSize: 10},
+	)
+
+	err := mm.Join(twittership.MatchRequest{Handle: "@Alice"})
+	if !errors.Is(err, twittership.ErrAlreadyQueued) {
+		t.Errorf("expected ErrAlreadyQueued joining twice but got %v", err)
+	}
+
+	err = mm.Join(twittership.MatchRequest{Handle: "dave", Variant: "salvo"})
+	if !errors.Is(err, twittership.ErrUnsupportedRules) {
+		t.Errorf("expected ErrUnsupportedRules for an unknown variant but got %v", err)
+	}
+
+	err = mm.Join(twittership.MatchRequest{Handle: "dave", BoardSize: 12})
+	if !errors.Is(err, twittership.ErrUnsupportedRules) {
+		t.Errorf("expected ErrUnsupportedRules for an unsupported board size but got %v", err)
+	}
+
+	matches, err := mm.Match()
+	if err != nil {
+		t.Fatalf("matching: %v", err)
+	}
+
+	if len(matches) != 1 || matches[0].Players != (twittership.Players{"bob", "carol"}) || matches[0].Rated {
+		t.Fatalf("expected bob and carol to play an unrated game but got %+v", matches)
+	}
+
+	// Finishing the unrated game doesn't change the ratings
+	err = m.Do(matches[0].GameID, func(g *twittership.Game) error {
+		return g.Forfeit(twittership.EnemySide, twittership.Forfeited)
+	})
+	if err != nil {
+		t.Fatalf("forfeiting game: %v", err)
+	}
+
+	bob, err := stats.Player("bob")
+	if err != nil {
+		t.Fatalf("loading bob: %v", err)
+	}
+
+	if bob.Wins != 1 || len(bob.RatingHistory) != 0 || bob.Rating != twittership.NewRating() {
+		t.Errorf("expected bob to win without the game being rated but got %+v", bob)
+	}
+
+	err = mm.Leave("alice")
+	if err != nil {
+		t.Fatalf("leaving the queue: %v", err)
+	}
+
+	err = mm.Leave("alice")
+	if !errors.Is(err, twittership.ErrNotQueued) {
+		t.Errorf("expected ErrNotQueued leaving twice but got %v", err)
+	}
+}
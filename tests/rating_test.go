@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"twittership"
+)
+
+func TestRatingUpdateMatchesTheGlicko2Example(t *testing.T) {
+	t.Parallel()
+
+	// The worked example from the Glicko-2 paper by Mark Glickman
+	player := twittership.Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	updated := player.Update([]twittership.RatingResult{
+		{Opponent: twittership.Rating{Rating: 1400, Deviation: 30}, Score: 1},
+		{Opponent: twittership.Rating{Rating: 1550, Deviation: 100}, Score: 0},
+		{Opponent: twittership.Rating{Rating: 1700, Deviation: 300}, Score: 0},
+	})
+
+	if math.Abs(updated.Rating-1464.06) > 0.01 || math.Abs(updated.Deviation-151.52) > 0.01 || math.Abs(updated.Volatility-0.05999) > 0.00001 {
+		t.Errorf("expected 1464.06 ± 151.52 with volatility 0.05999 but got %+v", updated)
+	}
+
+	idle := player.Update(nil)
+	if idle.Rating != player.Rating || idle.Deviation <= player.Deviation {
+		t.Errorf("expected a rating period without games to only grow the deviation but got %+v", idle)
+	}
+}
+
+// newRatedStatsStore creates a store holding players with the ratings provided, keyed by
+// handle, and a settled deviation.
+func newRatedStatsStore(t *testing.T, ratings map[string]float64) *twittership.MemoryStatsStore {
+	t.Helper()
+
+	store := twittership.NewMemoryStatsStore()
+	for handle, rating := range ratings {
+		err := store.SavePlayers(twittership.PlayerStats{Handle: handle, Rating: twittership.Rating{Rating: rating, Deviation: 80, Volatility: 0.06}})
+		if err != nil {
+			t.Fatalf("saving %s: %v", handle, err)
+		}
+	}
+
+	return store
+}
+
+func newRatingStats(t *testing.T) (*twittership.Stats, *twittership.Manager, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)}
+	stats := twittership.NewStats(newRatedStatsStore(t, nil))
+	stats.SetClock(clock)
+
+	m := twittership.NewManager()
+	m.SetStats(stats)
+
+	return stats, m, clock
+}
+
+func TestStatsRateGamesBetweenTwoPlayers(t *testing.T) {
+	t.Parallel()
+
+	stats, m, _ := newRatingStats(t)
+
+	playStatsGame(t, m, "rated", twittership.Players{"alice", "bob"}, twittership.PlayerSide)
+	playStatsGame(t, m, "unrated", twittership.Players{"alice", ""}, twittership.PlayerSide)
+
+	alice, err := stats.Player("alice")
+	if err != nil {
+		t.Fatalf("loading alice: %v", err)
+	}
+
+	bob, err := stats.Player("bob")
+	if err != nil {
+		t.Fatalf("loading bob: %v", err)
+	}
+
+	if alice.Rating.Rating <= 1500 || bob.Rating.Rating >= 1500 {
+		t.Errorf("expected the winner to gain and the loser to lose rating but got %v and %v", alice.Rating, bob.Rating)
+	}
+
+	if len(alice.RatingHistory) != 1 || alice.RatingHistory[0].Opponent != "bob" || alice.RatingHistory[0].Score != 1 {
+		t.Errorf("expected only the game against bob to be rated but got %+v", alice.RatingHistory)
+	}
+
+	if !alice.Rating.Provisional() {
+		t.Errorf("expected a rating after a single game to be provisional")
+	}
+}
+
+func TestStatsLeaderboardRanksEstablishedPlayers(t *testing.T) {
+	t.Parallel()
+
+	stats, m, clock := newRatingStats(t)
+
+	// Alice and bob play often enough to lose their provisional ratings, carol plays once
+	for i := 0; i < 18; i++ {
+		winner := twittership.PlayerSide
+		if i%3 == 2 {
+			winner = twittership.EnemySide
+		}
+
+		playStatsGame(t, m, fmt.Sprintf("game%d", i), twittership.Players{"alice", "bob"}, winner)
+		clock.Advance(24 * time.Hour)
+	}
+
+	weekAgo := clock.Now().Add(-7 * 24 * time.Hour)
+	playStatsGame(t, m, "carol", twittership.Players{"carol", "bob"}, twittership.PlayerSide)
+
+	entries, err := stats.Leaderboard(twittership.LeaderboardOptions{Since: weekAgo})
+	if err != nil {
+		t.Fatalf("loading leaderboard: %v", err)
+	}
+
+	if len(entries) != 2 || entries[0].Handle != "alice" || entries[1].Handle != "bob" || entries[0].Rank != 1 {
+		t.Fatalf("expected alice to rank above bob without carol but got %+v", entries)
+	}
+
+	if entries[0].Wins != 12 || entries[0].Losses != 6 {
+		t.Errorf("expected alice to be 12-6 but got %d-%d", entries[0].Wins, entries[0].Losses)
+	}
+
+	if entries[0].Change == 0 || entries[1].Change >= 0 {
+		t.Errorf("expected the rating changes over the last week but got %v and %v", entries[0].Change, entries[1].Change)
+	}
+
+	all, err := stats.Leaderboard(twittership.LeaderboardOptions{IncludeProvisional: true, Limit: 10})
+	if err != nil {
+		t.Fatalf("loading leaderboard: %v", err)
+	}
+
+	if len(all) != 3 {
+		t.Fatalf("expected carol to be included with a provisional rating but got %+v", all)
+	}
+
+	text := twittership.GetLeaderboardText(all)
+	if !strings.Contains(text, "carol") || !strings.Contains(text, "?") {
+		t.Errorf("expected the provisional rating of carol to be marked but got:\n%s", text)
+	}
+
+	li, err := twittership.NewLeaderboardImage(all, twittership.LeaderboardImageOptions{Width: 400})
+	if err != nil {
+		t.Fatalf("creating leaderboard image: %v", err)
+	}
+
+	if size := li.GetFullImage().Bounds().Size(); size.X != 400 || size.Y <= 4*36 {
+		t.Errorf("expected a 400 pixel wide image with a row per player but got %s", size)
+	}
+}
+
+func TestServerReturnsTheLeaderboard(t *testing.T) {
+	t.Parallel()
+
+	_, m, _ := newRatingStats(t)
+	playStatsGame(t, m, "rated", twittership.Players{"alice", "bob"}, twittership.EnemySide)
+
+	server := httptest.NewServer(twittership.NewServer(m, "../game_template.png"))
+	defer server.Close()
+
+	res, body := doRequest(t, server, http.MethodGet, "/leaderboard?provisional=true", "", "")
+	expectStatus(t, res, body, http.StatusOK)
+
+	var entries []twittership.LeaderboardEntry
+	err := json.Unmarshal(body, &entries)
+	if err != nil {
+		t.Fatalf("decoding leaderboard: %v", err)
+	}
+
+	if len(entries) != 2 || entries[0].Handle != "bob" {
+		t.Errorf("expected bob to lead after winning but got %+v", entries)
+	}
+
+	res, body = doRequest(t, server, http.MethodGet, "/leaderboard.png", "", "")
+	expectStatus(t, res, body, http.StatusNotFound)
+
+	res, body = doRequest(t, server, http.MethodGet, "/leaderboard.txt?provisional=true", "", "")
+	expectStatus(t, res, body, http.StatusOK)
+}
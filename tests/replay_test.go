@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"testing"
+	"time"
+	"twittership"
+)
+
+func newReplayTestGame(t *testing.T) twittership.Game {
+	return newTestGame(t, "H8;A2;H9;J10", "H8;A2;H9;J10")
+}
+
+func TestReplayGIFHasAFramePerMove(t *testing.T) {
+	t.Parallel()
+
+	game := newReplayTestGame(t)
+
+	var buf bytes.Buffer
+	err := twittership.RenderReplayGIF(game, twittership.ReplayOptions{Captions: true, FinalDelay: 5 * time.Second}, &buf)
+	if err != nil {
+		t.Fatalf("rendering replay: %v", err)
+	}
+
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("decoding replay: %v", err)
+	}
+
+	if len(anim.Image) != len(game.Moves()) {
+		t.Fatalf("expected %d frames but got %d", len(game.Moves()), len(anim.Image))
+	}
+
+	if anim.Config.Width != 882 || anim.Config.Height <= 491 {
+		t.Errorf("expected the captions to be drawn below the boards but the replay is %dx%d", anim.Config.Width, anim.Config.Height)
+	}
+
+	if anim.Delay[0] != 100 || anim.Delay[len(anim.Delay)-1] != 500 {
+		t.Errorf("expected the default delay and the final delay but got %v", anim.Delay)
+	}
+
+	full := image.Rect(0, 0, anim.Config.Width, anim.Config.Height)
+	if anim.Image[0].Bounds() != full {
+		t.Errorf("expected the first frame to be complete but it was %s", anim.Image[0].Bounds())
+	}
+
+	for i, frame := range anim.Image[1:] {
+		if frame.Bounds() == full {
+			t.Errorf("expected frame %d to only contain the pixels that changed", i+1)
+		}
+	}
+}
+
+func TestReplayGIFHighlightsTheNewestShot(t *testing.T) {
+	t.Parallel()
+
+	game := newReplayTestGame(t)
+
+	var buf bytes.Buffer
+	err := twittership.RenderReplayGIF(game, twittership.ReplayOptions{}, &buf)
+	if err != nil {
+		t.Fatalf("rendering replay: %v", err)
+	}
+
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("decoding replay: %v", err)
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, anim.Config.Width, anim.Config.Height))
+	for _, frame := range anim.Image {
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+	}
+
+	// The last move is the enemy volley at J10 on the player board
+	highlight := twittership.ClassicTheme.Highlight
+	if color.RGBAModel.Convert(canvas.At(40+9*40+2, 90+9*40+2)) != highlight {
+		t.Errorf("expected the newest shot to be highlighted")
+	}
+
+	// The previous move at J10 on the enemy board is no longer highlighted
+	if color.RGBAModel.Convert(canvas.At(481+9*40+2, 90+9*40+2)) == highlight {
+		t.Errorf("expected only the newest shot to be highlighted")
+	}
+}
+
+func TestReplayGIFErrors(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := twittership.RenderReplayGIF(twittership.NewGame(), twittership.ReplayOptions{}, &buf)
+	if !errors.Is(err, twittership.ErrNoMoves) {
+		t.Errorf("expected ErrNoMoves but got %v", err)
+	}
+
+	err = twittership.RenderReplayGIF(newReplayTestGame(t), twittership.ReplayOptions{MaxBytes: 1000}, &buf)
+	if !errors.Is(err, twittership.ErrReplayTooLarge) {
+		t.Errorf("expected ErrReplayTooLarge but got %v", err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be written when the replay is too large")
+	}
+}
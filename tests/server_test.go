@@ -0,0 +1,262 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"twittership"
+)
+
+type testGame struct {
+	ID          string `json:"id"`
+	PlayerToken string `json:"playerToken"`
+	EnemyToken  string `json:"enemyToken"`
+}
+
+func doRequest(t *testing.T, server *httptest.Server, method, path, token, body string) (*http.Response, []byte) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, server.URL+path, reader)
+	if err != nil {
+		t.Fatalf("creating request: %v", err)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("sending request: %v", err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("reading response: %v", err)
+	}
+
+	return res, resBody
+}
+
+func expectStatus(t *testing.T, res *http.Response, body []byte, status int) {
+	t.Helper()
+
+	if res.StatusCode != status {
+		t.Fatalf("expected status %d but got %d: %s", status, res.StatusCode, body)
+	}
+}
+
+func newTestServerGame(t *testing.T) (*httptest.Server, testGame) {
+	server := httptest.NewServer(twittership.NewServer(twittership.NewManager(), "../game_template.png"))
+
+	res, body := doRequest(t, server, http.MethodPost, "/games", "", "")
+	expectStatus(t, res, body, http.StatusCreated)
+
+	var game testGame
+	err := json.Unmarshal(body, &game)
+	if err != nil {
+		t.Fatalf("decoding game: %v", err)
+	}
+
+	for _, token := range []string{game.PlayerToken, game.EnemyToken} {
+		res, body = doRequest(t, server, http.MethodPost, "/games/"+game.ID+"/ships", token, `{"positions": "A1H;B8V;E3H;G3V;H8H"}`)
+		expectStatus(t, res, body, http.StatusOK)
+	}
+
+	return server, game
+}
+
+func TestServerRejectsRequestsWithoutAValidToken(t *testing.T) {
+	t.Parallel()
+
+	server, game := newTestServerGame(t)
+	defer server.Close()
+
+	res, body := doRequest(t, server, http.MethodGet, "/games/"+game.ID, "", "")
+	expectStatus(t, res, body, http.StatusUnauthorized)
+
+	res, body = doRequest(t, server, http.MethodPost, "/games/"+game.ID+"/volleys", "not-a-token", `{"position": "A1"}`)
+	expectStatus(t, res, body, http.StatusUnauthorized)
+
+	res, body = doRequest(t, server, http.MethodGet, "/games/missing", game.PlayerToken, "")
+	expectStatus(t, res, body, http.StatusNotFound)
+}
+
+func TestServerOnlyRevealsTheShipsOfTheRequestingSide(t *testing.T) {
+	t.Parallel()
+
+	server, game := newTestServerGame(t)
+	defer server.Close()
+
+	res, body := doRequest(t, server, http.MethodGet, "/games/"+game.ID, game.EnemyToken, "")
+	expectStatus(t, res, body, http.StatusOK)
+
+	var view twittership.PlayerView
+	err := json.Unmarshal(body, &view)
+	if err != nil {
+		t.Fatalf("decoding view: %v", err)
+	}
+
+	if view.Side != twittership.EnemySide {
+		t.Fatalf("expected the view to be for the enemy but it was for %s", view.Side)
+	}
+
+	if len(view.Own.Ships) != 5 || len(view.Opponent.Ships) != 0 {
+		t.Fatalf("expected 5 own ships and no opponent ships but got %d and %d", len(view.Own.Ships), len(view.Opponent.Ships))
+	}
+}
+
+func TestServerPlaysVolleysInTurn(t *testing.T) {
+	t.Parallel()
+
+	server, game := newTestServerGame(t)
+	defer server.Close()
+
+	path := "/games/" + game.ID + "/volleys"
+
+	res, body := doRequest(t, server, http.MethodPost, path, game.EnemyToken, `{"position": "A1"}`)
+	expectStatus(t, res, body, http.StatusConflict)
+
+	res, body = doRequest(t, server, http.MethodPost, path, game.PlayerToken, `{"position": "A1"}`)
+	expectStatus(t, res, body, http.StatusOK)
+
+	var volley struct {
+		Result string           `json:"result"`
+		Move   twittership.Move `json:"move"`
+	}
+	err := json.Unmarshal(body, &volley)
+	if err != nil {
+		t.Fatalf("decoding volley: %v", err)
+	}
+
+	if volley.Result != "Hit" || volley.Move.Position != "A1" || volley.Move.Side != twittership.PlayerSide {
+		t.Fatalf("unexpected volley response %s", body)
+	}
+
+	res, body = doRequest(t, server, http.MethodPost, path, game.EnemyToken, `{"position": "Z1"}`)
+	expectStatus(t, res, body, http.StatusBadRequest)
+
+	res, body = doRequest(t, server, http.MethodPost, path, game.EnemyToken, `{"position": "J10"}`)
+	expectStatus(t, res, body, http.StatusOK)
+
+	res, body = doRequest(t, server, http.MethodGet, "/games/"+game.ID+"/moves", game.EnemyToken, "")
+	expectStatus(t, res, body, http.StatusOK)
+
+	var moves []twittership.Move
+	err = json.Unmarshal(body, &moves)
+	if err != nil {
+		t.Fatalf("decoding moves: %v", err)
+	}
+
+	if len(moves) != 2 || moves[0].Position != "A1" || !moves[0].Hit || moves[1].Position != "J10" || moves[1].Hit {
+		t.Fatalf("unexpected moves %s", body)
+	}
+}
+
+func TestServerOnlyAcceptsASingleShotPerVolley(t *testing.T) {
+	t.Parallel()
+
+	server, game := newTestServerGame(t)
+	defer server.Close()
+
+	path := "/games/" + game.ID + "/volleys"
+	for _, position := range []string{"A1;A2;A3;A4;A5", "zzA1zz", "A11", "A0", ""} {
+		res, body := doRequest(t, server, http.MethodPost, path, game.PlayerToken, `{"position": "`+position+`"}`)
+		expectStatus(t, res, body, http.StatusBadRequest)
+	}
+
+	res, body := doRequest(t, server, http.MethodGet, "/games/"+game.ID+"/moves", game.PlayerToken, "")
+	expectStatus(t, res, body, http.StatusOK)
+
+	if strings.TrimSpace(string(body)) != "[]" {
+		t.Fatalf("expected no shots to be fired but got %s", body)
+	}
+}
+
+func TestServerAcceptsShipsAfterARejectedPlacement(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(twittership.NewServer(twittership.NewManager(), "../game_template.png"))
+	defer server.Close()
+
+	res, body := doRequest(t, server, http.MethodPost, "/games", "", "")
+	expectStatus(t, res, body, http.StatusCreated)
+
+	var game testGame
+	err := json.Unmarshal(body, &game)
+	if err != nil {
+		t.Fatalf("decoding game: %v", err)
+	}
+
+	path := "/games/" + game.ID + "/ships"
+
+	// An overlapping battleship, too few ships, a ship without a direction and too many ships
+	for _, positions := range []string{"A1H;A2H;E3H;G3V;H8H", "A1H", "A1H;B8V;E3H;G3V;H8", "A1H;B8V;E3H;G3V;H8H;J1H"} {
+		res, body = doRequest(t, server, http.MethodPost, path, game.PlayerToken, `{"positions": "`+positions+`"}`)
+		expectStatus(t, res, body, http.StatusBadRequest)
+	}
+
+	res, body = doRequest(t, server, http.MethodPost, path, game.PlayerToken, `{"positions": "A1H;B8V;E3H;G3V;H8H"}`)
+	expectStatus(t, res, body, http.StatusOK)
+}
+
+func TestServerRendersTheBoardForTheRequestingSide(t *testing.T) {
+	t.Parallel()
+
+	server, game := newTestServerGame(t)
+	defer server.Close()
+
+	res, body := doRequest(t, server, http.MethodGet, "/games/"+game.ID+"/board.png", game.PlayerToken, "")
+	expectStatus(t, res, body, http.StatusOK)
+
+	img, err := png.Decode(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("decoding board image: %v", err)
+	}
+
+	if img.Bounds().Dx() != 882 || img.Bounds().Dy() != 491 {
+		t.Fatalf("unexpected board image size %s", img.Bounds())
+	}
+
+	res, body = doRequest(t, server, http.MethodGet, "/games/"+game.ID+"/board.txt", game.EnemyToken, "")
+	expectStatus(t, res, body, http.StatusOK)
+
+	if !strings.Contains(string(body), "PLAYER BOARD") {
+		t.Fatalf("unexpected board text %s", body)
+	}
+}
+
+func TestServerRendersTheBoardInTheRequestedFormat(t *testing.T) {
+	t.Parallel()
+
+	server, game := newTestServerGame(t)
+	defer server.Close()
+
+	res, body := doRequest(t, server, http.MethodGet, "/games/"+game.ID+"/board.png?format=jpeg", game.PlayerToken, "")
+	expectStatus(t, res, body, http.StatusOK)
+
+	if res.Header.Get("Content-Type") != "image/jpeg" {
+		t.Fatalf("unexpected content type %s", res.Header.Get("Content-Type"))
+	}
+
+	_, err := jpeg.Decode(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("decoding board image: %v", err)
+	}
+
+	res, body = doRequest(t, server, http.MethodGet, "/games/"+game.ID+"/board.png?format=bmp", game.PlayerToken, "")
+	expectStatus(t, res, body, http.StatusBadRequest)
+}
@@ -0,0 +1,354 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"twittership"
+)
+
+var (
+	// statsShipTiles are every tile of the ships placed by statsShips
+	statsShipTiles = []string{"A1", "A2", "A3", "A4", "A5", "B8", "C8", "D8", "E8", "E3", "E4", "E5", "G3", "H3", "I3", "H8", "H9"}
+	// statsMissTiles are tiles that don't have a ship on them
+	statsMissTiles = []string{"J1", "J2", "J3", "J4", "J5", "J6", "J7", "J8", "J9", "J10", "F1", "F2", "F3", "F4", "F5", "F6", "F7"}
+)
+
+const statsShips = "A1H;B8V;E3H;G3V;H8H"
+
+// playStatsGame plays a game in the manager that winner wins by sinking every ship, the loser
+// only misses.
+func playStatsGame(t *testing.T, m *twittership.Manager, id string, players twittership.Players, winner twittership.Side) {
+	t.Helper()
+
+	err := m.Create(id)
+	if err != nil {
+		t.Fatalf("creating game: %v", err)
+	}
+
+	err = m.SetPlayers(id, players)
+	if err != nil {
+		t.Fatalf("setting players: %v", err)
+	}
+
+	err = m.Do(id, func(g *twittership.Game) error {
+		err := g.LoadPlayerShips(statsShips)
+		if err != nil {
+			return err
+		}
+
+		return g.LoadEnemyShips(statsShips)
+	})
+	if err != nil {
+		t.Fatalf("loading ships: %v", err)
+	}
+
+	fired := [2]int{}
+	finished := false
+	for !finished {
+		err = m.Do(id, func(g *twittership.Game) error {
+			side := g.Turn()
+			tiles := statsMissTiles
+			if side == winner {
+				tiles = statsShipTiles
+			}
+
+			_, err := g.Volley(side, tiles[fired[side]])
+			fired[side]++
+			finished = g.IsFinished()
+			return err
+		})
+		if err != nil {
+			t.Fatalf("firing volley: %v", err)
+		}
+	}
+}
+
+func TestStatsRecordFinishedGamesForBothPlayers(t *testing.T) {
+	t.Parallel()
+
+	stats := twittership.NewStats(twittership.NewMemoryStatsStore())
+	m := twittership.NewManager()
+	m.SetStats(stats)
+
+	playStatsGame(t, m, "first", twittership.Players{"@alice", "@bob"}, twittership.PlayerSide)
+	playStatsGame(t, m, "second", twittership.Players{"@bob", "@alice"}, twittership.EnemySide)
+	playStatsGame(t, m, "third", twittership.Players{"@alice", "@bob"}, twittership.EnemySide)
+
+	alice, err := stats.Player("alice")
+	if err != nil {
+		t.Fatalf("loading alice: %v", err)
+	}
+
+	if alice.Games != 3 || alice.Wins != 2 || alice.Losses != 1 || alice.LongestStreak != 2 || alice.CurrentStreak != 0 {
+		t.Errorf("expected alice to have won 2 of 3 games in a row but got %+v", alice)
+	}
+
+	if alice.WinningShots != 34 || alice.AverageShotsToWin() != 17 {
+		t.Errorf("expected alice to need 17 shots to win but got %v", alice.AverageShotsToWin())
+	}
+
+	// Alice fired first in the lost game so missed 17 times before the last ship was sunk
+	if alice.Shots != 51 || alice.Hits != 34 {
+		t.Errorf("expected alice to hit 34 of 51 shots but got %d of %d", alice.Hits, alice.Shots)
+	}
+
+	if openings := alice.FavouriteOpenings(2); len(openings) != 2 || openings[0] != "A1" || openings[1] != "J1" {
+		t.Errorf("expected the favourite openings of alice to be A1 and J1 but got %v", openings)
+	}
+
+	bob, err := stats.Player("@Bob")
+	if err != nil {
+		t.Fatalf("loading bob: %v", err)
+	}
+
+	if bob.Games != 3 || bob.Wins != 1 || bob.CurrentStreak != 1 || bob.Forfeits != 0 {
+		t.Errorf("expected bob to have won the last of 3 games but got %+v", bob)
+	}
+
+	_, err = stats.Player("carol")
+	if !errors.Is(err, twittership.ErrPlayerNotFound) {
+		t.Errorf("expected ErrPlayerNotFound for a player without games but got %v", err)
+	}
+}
+
+func TestStatsCountForfeitsAndIgnoreGamesWithoutPlayers(t *testing.T) {
+	t.Parallel()
+
+	stats := twittership.NewStats(twittership.NewMemoryStatsStore())
+	m := twittership.NewManager()
+	m.SetStats(stats)
+
+	for _, id := range []string{"forfeited", "anonymous"} {
+		err := m.Create(id)
+		if err != nil {
+			t.Fatalf("creating game: %v", err)
+		}
+	}
+
+	err := m.SetPlayers("forfeited", twittership.Players{"alice", "bob"})
+	if err != nil {
+		t.Fatalf("setting players: %v", err)
+	}
+
+	for _, id := range []string{"forfeited", "anonymous"} {
+		err = m.Do(id, func(g *twittership.Game) error {
+			return g.Forfeit(twittership.EnemySide, twittership.Forfeited)
+		})
+		if err != nil {
+			t.Fatalf("forfeiting game: %v", err)
+		}
+	}
+
+	// Further calls on a finished game don't record it again
+	err = m.Do("forfeited", func(g *twittership.Game) error { return nil })
+	if err != nil {
+		t.Fatalf("doing nothing: %v", err)
+	}
+
+	bob, err := stats.Player("bob")
+	if err != nil {
+		t.Fatalf("loading bob: %v", err)
+	}
+
+	if bob.Games != 1 || bob.Losses != 1 || bob.Forfeits != 1 || bob.Shots != 0 {
+		t.Errorf("expected bob to have forfeited a single game but got %+v", bob)
+	}
+
+	err = stats.RecordGame(twittership.Players{"alice", "bob"}, twittership.NewGame())
+	if !errors.Is(err, twittership.ErrGameNotFinished) {
+		t.Errorf("expected ErrGameNotFinished recording a game being played but got %v", err)
+	}
+}
+
+// unavailableStatsStore fails every save while unavailable is set.
+type unavailableStatsStore struct {
+	*twittership.MemoryStatsStore
+	unavailable bool
+}
+
+func (s *unavailableStatsStore) SavePlayers(stats ...twittership.PlayerStats) error {
+	if s.unavailable {
+		return errors.New("store unavailable")
+	}
+
+	return s.MemoryStatsStore.SavePlayers(stats...)
+}
+
+func TestStatsFailuresDoNotFailTheGameAndAreRetried(t *testing.T) {
+	t.Parallel()
+
+	store := &unavailableStatsStore{MemoryStatsStore: twittership.NewMemoryStatsStore(), unavailable: true}
+	stats := twittership.NewStats(store)
+	m := twittership.NewManager()
+	m.SetStats(stats)
+
+	var failures []string
+	m.SetStatsErrorHandler(func(id string, err error) {
+		failures = append(failures, id)
+	})
+
+	// The winning volley succeeds even though the statistics can't be saved
+	playStatsGame(t, m, "unsaved", twittership.Players{"alice", "bob"}, twittership.PlayerSide)
+
+	g, err := m.Get("unsaved")
+	if err != nil || !g.IsFinished() {
+		t.Fatalf("expected the finished game to be readable but got %v", err)
+	}
+
+	if len(failures) != 1 || failures[0] != "unsaved" {
+		t.Fatalf("expected the failure to be reported once but got %v", failures)
+	}
+
+	store.unavailable = false
+	m.RetryStats()
+
+	alice, err := stats.Player("alice")
+	if err != nil {
+		t.Fatalf("loading alice: %v", err)
+	}
+
+	if alice.Games != 1 || alice.Wins != 1 {
+		t.Errorf("expected the game to be recorded once retried but got %+v", alice)
+	}
+
+	m.RetryStats()
+
+	alice, err = stats.Player("alice")
+	if err != nil || alice.Games != 1 {
+		t.Errorf("expected the game to only be recorded once but got %+v, %v", alice, err)
+	}
+}
+
+func TestStatsRecordBothSidesOfAGameAgainstYourself(t *testing.T) {
+	t.Parallel()
+
+	stats := twittership.NewStats(twittership.NewMemoryStatsStore())
+	m := twittership.NewManager()
+	m.SetStats(stats)
+
+	playStatsGame(t, m, "self", twittership.Players{"alice", "@Alice"}, twittership.PlayerSide)
+
+	alice, err := stats.Player("alice")
+	if err != nil {
+		t.Fatalf("loading alice: %v", err)
+	}
+
+	if alice.Games != 2 || alice.Wins != 1 || alice.Losses != 1 || len(alice.RatingHistory) != 0 {
+		t.Errorf("expected the win and the loss to be recorded without a rating change but got %+v", alice)
+	}
+}
+
+func TestFileStatsStorePersistsPlayers(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "twittership")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "stats.json")
+
+	store, err := twittership.NewFileStatsStore(path)
+	if err != nil {
+		t.Fatalf("creating store: %v", err)
+	}
+
+	err = store.SavePlayers(twittership.PlayerStats{Handle: "alice", Games: 2, Wins: 1, Openings: map[string]int{"E5": 2}})
+	if err != nil {
+		t.Fatalf("saving player: %v", err)
+	}
+
+	reopened, err := twittership.NewFileStatsStore(path)
+	if err != nil {
+		t.Fatalf("reopening store: %v", err)
+	}
+
+	alice, err := reopened.LoadPlayer("alice")
+	if err != nil {
+		t.Fatalf("loading alice: %v", err)
+	}
+
+	if alice.Games != 2 || alice.Wins != 1 || alice.Openings["E5"] != 2 {
+		t.Errorf("expected the saved statistics to be loaded but got %+v", alice)
+	}
+}
+
+func TestServerReturnsPlayerStats(t *testing.T) {
+	t.Parallel()
+
+	m := twittership.NewManager()
+	m.SetStats(twittership.NewStats(twittership.NewMemoryStatsStore()))
+	server := httptest.NewServer(twittership.NewServer(m, "../game_template.png"))
+	defer server.Close()
+
+	err := m.Create("match")
+	if err != nil {
+		t.Fatalf("creating game: %v", err)
+	}
+
+	err = m.SetPlayers("match", twittership.Players{"@alice", "@bob"})
+	if err != nil {
+		t.Fatalf("setting players: %v", err)
+	}
+
+	err = m.Do("match", func(g *twittership.Game) error {
+		return g.Forfeit(twittership.PlayerSide, twittership.Forfeited)
+	})
+	if err != nil {
+		t.Fatalf("forfeiting game: %v", err)
+	}
+
+	res, body := doRequest(t, server, http.MethodGet, "/players/bob", "", "")
+	expectStatus(t, res, body, http.StatusOK)
+
+	var bob twittership.PlayerStats
+	err = json.Unmarshal(body, &bob)
+	if err != nil {
+		t.Fatalf("decoding stats: %v", err)
+	}
+
+	if bob.Handle != "@bob" || bob.Wins != 1 {
+		t.Errorf("expected @bob to have won a game but got %+v", bob)
+	}
+
+	res, body = doRequest(t, server, http.MethodGet, "/players/carol", "", "")
+	expectStatus(t, res, body, http.StatusNotFound)
+}
+
+func TestServerGamesAreNotRecordedUnderHandles(t *testing.T) {
+	t.Parallel()
+
+	m := twittership.NewManager()
+	m.SetStats(twittership.NewStats(twittership.NewMemoryStatsStore()))
+	server := httptest.NewServer(twittership.NewServer(m, "../game_template.png"))
+	defer server.Close()
+
+	// Whoever creates the game holds both tokens so it can't be recorded for anybody
+	res, body := doRequest(t, server, http.MethodPost, "/games", "", `{"player": "@alice", "enemy": "@bob"}`)
+	expectStatus(t, res, body, http.StatusCreated)
+
+	var game testGame
+	err := json.Unmarshal(body, &game)
+	if err != nil {
+		t.Fatalf("decoding game: %v", err)
+	}
+
+	err = m.Do(game.ID, func(g *twittership.Game) error {
+		return g.Forfeit(twittership.PlayerSide, twittership.Forfeited)
+	})
+	if err != nil {
+		t.Fatalf("forfeiting game: %v", err)
+	}
+
+	for _, handle := range []string{"alice", "bob"} {
+		res, body = doRequest(t, server, http.MethodGet, "/players/"+handle, "", "")
+		expectStatus(t, res, body, http.StatusNotFound)
+	}
+}
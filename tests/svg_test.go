@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"twittership"
+)
+
+var svgParams = []struct {
+	name          string
+	game          func(t *testing.T) twittership.Game
+	theme         twittership.Theme
+	tileSize      int
+	expectedImage string
+}{
+	{
+		name:          "normal game player and enemy have same positions",
+		game:          newImageTestGame,
+		theme:         twittership.ClassicTheme,
+		expectedImage: "game_1.svg",
+	},
+	{
+		name:          "sunk ships on both boards with the color blind theme",
+		game:          newFleetTestGame,
+		theme:         twittership.ColorBlindTheme,
+		expectedImage: "game_sunk_colorblind.svg",
+	},
+	{
+		name:          "smaller tiles scale the labels and titles",
+		game:          newFleetTestGame,
+		theme:         twittership.ClassicTheme,
+		tileSize:      24,
+		expectedImage: "game_sunk_tile_24.svg",
+	},
+}
+
+// TestSVGMatchesTheGoldenFiles renders games as SVG and compares them to the expected files
+// byte for byte.
+func TestSVGMatchesTheGoldenFiles(t *testing.T) {
+	t.Parallel()
+
+	for _, svgParam := range svgParams {
+		t.Run(svgParam.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := twittership.RenderSVG(svgParam.game(t), twittership.SVGOptions{Theme: svgParam.theme, TileSize: svgParam.tileSize}, &buf)
+			if err != nil {
+				t.Fatalf("rendering svg: %v", err)
+			}
+
+			expected, err := ioutil.ReadFile("assets/" + svgParam.expectedImage)
+			if err != nil {
+				t.Fatalf("reading expected svg: %v", err)
+			}
+
+			if !bytes.Equal(expected, buf.Bytes()) {
+				t.Fatalf("svg did not match %s, got:\n%s", svgParam.expectedImage, buf.String())
+			}
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestSVGReturnsWriteErrors(t *testing.T) {
+	t.Parallel()
+
+	err := twittership.RenderSVG(newImageTestGame(t), twittership.SVGOptions{}, failingWriter{})
+	if err == nil {
+		t.Fatalf("expected the write error to be returned")
+	}
+}
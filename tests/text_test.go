@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"twittership"
+)
+
+var textParams = []struct {
+	name         string
+	opts         twittership.TextOptions
+	expectedText string
+}{
+	{
+		name:         "classic with colors",
+		opts:         twittership.TextOptions{Color: twittership.ColorAlways},
+		expectedText: "text_classic_color.txt",
+	},
+	{
+		name:         "classic without colors with the legend and fleet status",
+		opts:         twittership.TextOptions{Color: twittership.ColorNever, Legend: true, FleetStatus: true},
+		expectedText: "text_classic_plain_full.txt",
+	},
+	{
+		name:         "ascii borders",
+		opts:         twittership.TextOptions{Color: twittership.ColorNever, Borders: twittership.BorderASCII},
+		expectedText: "text_ascii.txt",
+	},
+	{
+		name:         "unicode borders with the legend and fleet status",
+		opts:         twittership.TextOptions{Color: twittership.ColorNever, Borders: twittership.BorderUnicode, Legend: true, FleetStatus: true},
+		expectedText: "text_unicode_full.txt",
+	},
+	{
+		name:         "unicode borders with colors for the enemy board without fog of war",
+		opts:         twittership.TextOptions{Color: twittership.ColorAlways, Borders: twittership.BorderUnicode, Boards: twittership.EnemyBoardOnly, RevealEnemyShips: true},
+		expectedText: "text_unicode_enemy_revealed.txt",
+	},
+	{
+		name:         "classic player board only",
+		opts:         twittership.TextOptions{Color: twittership.ColorNever, Boards: twittership.PlayerBoardOnly},
+		expectedText: "text_classic_player.txt",
+	},
+}
+
+func TestTextRendererMatchesTheGoldenFiles(t *testing.T) {
+	for _, textParam := range textParams {
+		t.Run(textParam.name, func(t *testing.T) {
+			text := twittership.NewTextRenderer(textParam.opts).Render(newFleetTestGame(t))
+
+			expected, err := ioutil.ReadFile("assets/" + textParam.expectedText)
+			if err != nil {
+				t.Fatalf("reading expected text: %v", err)
+			}
+
+			if text != string(expected) {
+				t.Fatalf("text did not match %s, got:\n%s", textParam.expectedText, text)
+			}
+		})
+	}
+}
+
+func TestGameTextIsTheClassicColorLayout(t *testing.T) {
+	game := newFleetTestGame(t)
+
+	expected := twittership.NewTextRenderer(twittership.TextOptions{Color: twittership.ColorAlways}).Render(game)
+	if twittership.GetGameTextFromGame(game) != expected {
+		t.Fatalf("expected GetGameTextFromGame to use the classic layout with colors")
+	}
+}
+
+func TestTextRendererAutoColorOnlyUsesColorsForTerminals(t *testing.T) {
+	var buf bytes.Buffer
+	err := twittership.NewTextRenderer(twittership.TextOptions{}).Write(&buf, newFleetTestGame(t))
+	if err != nil {
+		t.Fatalf("writing game text: %v", err)
+	}
+
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("expected no colors when writing to a buffer but got:\n%s", buf.String())
+	}
+}
+
+func TestTextRendererRespectsNoColor(t *testing.T) {
+	previous, set := os.LookupEnv("NO_COLOR")
+	_ = os.Setenv("NO_COLOR", "1")
+	defer func() {
+		if set {
+			_ = os.Setenv("NO_COLOR", previous)
+		} else {
+			_ = os.Unsetenv("NO_COLOR")
+		}
+	}()
+
+	text := twittership.NewTextRenderer(twittership.TextOptions{}).Render(newFleetTestGame(t))
+	if strings.Contains(text, "\x1b[") {
+		t.Errorf("expected no colors when NO_COLOR is set but got:\n%s", text)
+	}
+}
+
+func TestTextRendererLinesUpBoardsWithoutShips(t *testing.T) {
+	game := twittership.NewGame()
+	err := game.LoadPlayerShips("A1H;B8V;E3H;G3V;H8H")
+	if err != nil {
+		t.Fatalf("setting player ships: %v", err)
+	}
+
+	for _, borders := range []twittership.BorderStyle{twittership.BorderClassic, twittership.BorderASCII, twittership.BorderUnicode} {
+		text := twittership.NewTextRenderer(twittership.TextOptions{Color: twittership.ColorNever, Borders: borders, FleetStatus: true}).Render(game)
+		if strings.Count(text, "Afloat") != 5 {
+			t.Errorf("expected only the player fleet to be listed but got:\n%s", text)
+		}
+	}
+}
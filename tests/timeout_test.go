@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+	"time"
+	"twittership"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func newTimeoutManager(t *testing.T, notices *[]twittership.TimeoutNotice) (*twittership.Manager, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)}
+	m := twittership.NewManager()
+	m.SetClock(clock)
+	m.SetTimeoutPolicy(twittership.TimeoutPolicy{
+		TurnDeadline: 24 * time.Hour,
+		Reminders:    []time.Duration{time.Hour, 12 * time.Hour},
+	}, func(notice twittership.TimeoutNotice) {
+		*notices = append(*notices, notice)
+	})
+
+	err := m.Create("game")
+	if err != nil {
+		t.Fatalf("creating game: %v", err)
+	}
+
+	err = m.Do("game", func(g *twittership.Game) error {
+		err := g.LoadPlayerShips("A1H;B8V;E3H;G3V;H8H")
+		if err != nil {
+			return err
+		}
+
+		return g.LoadEnemyShips("A1H;B8V;E3H;G3V;H8H")
+	})
+	if err != nil {
+		t.Fatalf("loading ships: %v", err)
+	}
+
+	return m, clock
+}
+
+func TestManagerSendsRemindersAtEachThresholdOnce(t *testing.T) {
+	t.Parallel()
+
+	var notices []twittership.TimeoutNotice
+	m, clock := newTimeoutManager(t, &notices)
+
+	clock.Advance(11 * time.Hour)
+	m.CheckTimeouts()
+	if len(notices) != 0 {
+		t.Fatalf("expected no reminders before the first threshold but got %d", len(notices))
+	}
+
+	clock.Advance(time.Hour)
+	m.CheckTimeouts()
+	m.CheckTimeouts()
+	if len(notices) != 1 {
+		t.Fatalf("expected one reminder at the 12h threshold but got %d", len(notices))
+	}
+
+	if notices[0].Side != twittership.PlayerSide || notices[0].Remaining != 12*time.Hour || notices[0].Forfeit {
+		t.Fatalf("unexpected reminder %+v", notices[0])
+	}
+
+	clock.Advance(11 * time.Hour)
+	m.CheckTimeouts()
+	if len(notices) != 2 || notices[1].Remaining != time.Hour {
+		t.Fatalf("expected a second reminder at the 1h threshold but got %+v", notices)
+	}
+}
+
+func TestManagerResetsTheDeadlineWhenASideMoves(t *testing.T) {
+	t.Parallel()
+
+	var notices []twittership.TimeoutNotice
+	m, clock := newTimeoutManager(t, &notices)
+
+	clock.Advance(20 * time.Hour)
+	_, err := m.PlayerVolley("game", "A1")
+	if err != nil {
+		t.Fatalf("player volley: %v", err)
+	}
+
+	deadline, ok, err := m.Deadline("game")
+	if err != nil || !ok {
+		t.Fatalf("expected a deadline but got ok: %v err: %v", ok, err)
+	}
+
+	if !deadline.Equal(clock.Now().Add(24 * time.Hour)) {
+		t.Fatalf("expected the deadline to be reset to 24h from now but it was %s", deadline)
+	}
+
+	clock.Advance(23 * time.Hour)
+	m.CheckTimeouts()
+
+	g, err := m.Get("game")
+	if err != nil {
+		t.Fatalf("getting game: %v", err)
+	}
+
+	if g.IsFinished() {
+		t.Fatalf("game should not have timed out after the deadline was reset")
+	}
+
+	if len(notices) != 1 || notices[0].Side != twittership.EnemySide {
+		t.Fatalf("expected a single reminder for the enemy but got %+v", notices)
+	}
+}
+
+func TestManagerForfeitsGamesThatPassTheDeadline(t *testing.T) {
+	t.Parallel()
+
+	var notices []twittership.TimeoutNotice
+	m, clock := newTimeoutManager(t, &notices)
+
+	_, err := m.PlayerVolley("game", "A1")
+	if err != nil {
+		t.Fatalf("player volley: %v", err)
+	}
+
+	clock.Advance(25 * time.Hour)
+	m.CheckTimeouts()
+
+	if len(notices) != 1 || !notices[0].Forfeit || notices[0].Side != twittership.EnemySide {
+		t.Fatalf("expected a single forfeit notice for the enemy but got %+v", notices)
+	}
+
+	g, err := m.Get("game")
+	if err != nil {
+		t.Fatalf("getting game: %v", err)
+	}
+
+	winner, finished := g.Winner()
+	if !finished || winner != twittership.PlayerSide || g.FinishReason() != twittership.TimedOut {
+		t.Fatalf("expected the player to win by timeout but got winner: %s finished: %v reason: %s", winner, finished, g.FinishReason())
+	}
+
+	_, err = m.EnemyVolley("game", "A1")
+	if err == nil {
+		t.Fatalf("expected volleys to be rejected after the game timed out")
+	}
+
+	clock.Advance(25 * time.Hour)
+	m.CheckTimeouts()
+	if len(notices) != 1 {
+		t.Fatalf("expected finished games to be ignored but got %d notices", len(notices))
+	}
+}
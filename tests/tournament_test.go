@@ -0,0 +1,467 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"twittership"
+)
+
+func newTestTournament(t *testing.T, ratings map[string]float64, handles []string, opts twittership.TournamentOptions) (*twittership.Tournament, *twittership.Manager) {
+	t.Helper()
+
+	store := newRatedStatsStore(t, ratings)
+
+	m := twittership.NewManager()
+	m.SetStats(twittership.NewStats(store))
+
+	tour, err := twittership.NewTournament(m, "cup", handles, opts)
+	if err != nil {
+		t.Fatalf("creating tournament: %v", err)
+	}
+
+	return tour, m
+}
+
+// playTournament plays every game of the tournament, winner picks the handle that wins each
+// game.
+func playTournament(t *testing.T, m *twittership.Manager, tour *twittership.Tournament, winner func(game twittership.TournamentGame) string) []twittership.TournamentGame {
+	t.Helper()
+
+	var played []twittership.TournamentGame
+	for i := 0; i < 100; i++ {
+		games, err := tour.Advance()
+		if err != nil {
+			t.Fatalf("advancing tournament: %v", err)
+		}
+
+		if len(games) == 0 {
+			return played
+		}
+
+		for _, game := range games {
+			loser := twittership.PlayerSide
+			if winner(game) == game.Players[twittership.PlayerSide] {
+				loser = twittership.EnemySide
+			}
+
+			err := m.Do(game.GameID, func(g *twittership.Game) error {
+				return g.Forfeit(loser, twittership.Forfeited)
+			})
+			if err != nil {
+				t.Fatalf("finishing game %s: %v", game.GameID, err)
+			}
+		}
+
+		played = append(played, games...)
+	}
+
+	t.Fatalf("expected the tournament to finish")
+	return nil
+}
+
+// higherRated wins every game for the player with the lower seed, which is the alphabetical
+// order of the handles in these tests.
+func higherRated(game twittership.TournamentGame) string {
+	if game.Players[0] < game.Players[1] {
+		return game.Players[0]
+	}
+
+	return game.Players[1]
+}
+
+var tournamentRatings = map[string]float64{"alice": 1900, "bob": 1800, "carol": 1700, "dave": 1600, "erin": 1500, "frank": 1400}
+
+func TestTournamentSeedsByRatingAndGivesTheTopSeedsByes(t *testing.T) {
+	t.Parallel()
+
+	tour, _ := newTestTournament(t, tournamentRatings, []string{"frank", "carol", "alice", "erin", "bob", "dave"}, twittership.TournamentOptions{})
+
+	var seeds []string
+	for _, e := range tour.Entrants() {
+		seeds = append(seeds, e.Handle)
+	}
+
+	if strings.Join(seeds, ",") != "alice,bob,carol,dave,erin,frank" {
+		t.Fatalf("expected the players to be seeded by rating but got %v", seeds)
+	}
+
+	matches := tour.Matches()
+	if len(matches) != 7 {
+		t.Fatalf("expected a bracket of 8 with 7 matches but got %d", len(matches))
+	}
+
+	// Seeds 1 and 2 get byes and can only meet in the final
+	first, second := matches[0], matches[2]
+	if !first.Bye || first.Winner != "alice" || !second.Bye || second.Winner != "bob" {
+		t.Errorf("expected alice and bob to get byes but got %+v and %+v", first, second)
+	}
+
+	if matches[1].Players != [2]string{"dave", "erin"} || matches[3].Players != [2]string{"carol", "frank"} {
+		t.Errorf("expected 4 v 5 and 3 v 6 in the first round but got %v and %v", matches[1].Players, matches[3].Players)
+	}
+
+	if matches[4].Players[0] != "alice" || matches[5].Players[0] != "bob" {
+		t.Errorf("expected the byes to move alice and bob to the semifinals but got %v and %v", matches[4].Players, matches[5].Players)
+	}
+}
+
+func TestTournamentRejectsInvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	m := twittership.NewManager()
+
+	_, err := twittership.NewTournament(m, "cup", []string{"alice"}, twittership.TournamentOptions{})
+	if !errors.Is(err, twittership.ErrTooFewEntrants) {
+		t.Errorf("expected ErrTooFewEntrants but got %v", err)
+	}
+
+	_, err = twittership.NewTournament(m, "cup", []string{"alice", "@Alice"}, twittership.TournamentOptions{})
+	if !errors.Is(err, twittership.ErrDuplicateEntrant) {
+		t.Errorf("expected ErrDuplicateEntrant but got %v", err)
+	}
+
+	_, err = twittership.NewTournament(m, "cup", []string{"alice", "bob"}, twittership.TournamentOptions{BestOf: 2})
+	if !errors.Is(err, twittership.ErrInvalidBestOf) {
+		t.Errorf("expected ErrInvalidBestOf but got %v", err)
+	}
+
+	_, err = twittership.NewTournament(m, "cup", []string{"alice", "bob"}, twittership.TournamentOptions{Format: 7})
+	if err == nil {
+		t.Errorf("expected an unknown format to be rejected")
+	}
+
+	if name := twittership.TournamentFormat(7).String(); name != "TournamentFormat(7)" {
+		t.Errorf("expected an unknown format to be named by its value but got %s", name)
+	}
+}
+
+func TestTournamentGamesHaveATokenForEachPlayer(t *testing.T) {
+	t.Parallel()
+
+	tour, m := newTestTournament(t, tournamentRatings, []string{"alice", "bob"}, twittership.TournamentOptions{})
+
+	server := httptest.NewServer(twittership.NewServer(m, "../game_template.png"))
+	defer server.Close()
+
+	games, err := tour.Advance()
+	if err != nil {
+		t.Fatalf("advancing tournament: %v", err)
+	}
+
+	if len(games) != 1 {
+		t.Fatalf("expected one game but got %+v", games)
+	}
+
+	game := games[0]
+	if game.Tokens[0] == "" || game.Tokens[0] == game.Tokens[1] {
+		t.Fatalf("expected a different token for each player but got %v", game.Tokens)
+	}
+
+	for _, token := range game.Tokens {
+		res, body := doRequest(t, server, http.MethodPost, "/games/"+game.GameID+"/ships", token, `{"positions": "A1H;B8V;E3H;G3V;H8H"}`)
+		expectStatus(t, res, body, http.StatusOK)
+	}
+}
+
+func TestSingleEliminationPlaysBestOfMatches(t *testing.T) {
+	t.Parallel()
+
+	tour, m := newTestTournament(t, tournamentRatings, []string{"alice", "bob", "carol", "dave"}, twittership.TournamentOptions{
+		Format:  twittership.SingleElimination,
+		BestOf:  3,
+		Unrated: true,
+	})
+
+	// The lower seed takes the first game of every match but loses the next two
+	played := playTournament(t, m, tour, func(game twittership.TournamentGame) string {
+		if strings.HasSuffix(game.GameID, "-1") {
+			return game.Players[1]
+		}
+
+		return higherRated(game)
+	})
+
+	if len(played) != 9 {
+		t.Fatalf("expected three matches of three games but got %d games", len(played))
+	}
+
+	// Players swap sides after every game
+	if played[0].Players != (twittership.Players{"alice", "dave"}) || played[2].Players != (twittership.Players{"dave", "alice"}) {
+		t.Errorf("expected the players to swap sides but got %v and %v", played[0].Players, played[2].Players)
+	}
+
+	champion, ok := tour.Champion()
+	if !ok || champion != "alice" {
+		t.Fatalf("expected alice to win but got %q", champion)
+	}
+
+	final := tour.Matches()[2]
+	if final.Players != [2]string{"alice", "bob"} || final.Wins != [2]int{2, 1} || len(final.GameIDs) != 3 {
+		t.Errorf("expected alice to beat bob 2-1 in the final but got %+v", final)
+	}
+
+	standings := tour.Standings()
+	if standings[1].Handle != "bob" || !standings[1].Eliminated || standings[0].MatchWins != 2 || standings[0].GameWins != 4 {
+		t.Errorf("expected bob to finish second but got %+v", standings)
+	}
+}
+
+func TestDoubleEliminationReplaysTheGrandFinal(t *testing.T) {
+	t.Parallel()
+
+	tour, m := newTestTournament(t, tournamentRatings, []string{"alice", "bob", "carol", "dave"}, twittership.TournamentOptions{
+		Format: twittership.DoubleElimination,
+	})
+
+	// Bob loses to alice in the winners bracket then beats her in the first grand final
+	playTournament(t, m, tour, func(game twittership.TournamentGame) string {
+		if game.MatchID == "GF" {
+			return "bob"
+		}
+
+		return higherRated(game)
+	})
+
+	matches := tour.Matches()
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.ID)
+	}
+
+	if strings.Join(ids, ",") != "W1-1,W1-2,W2-1,L1-1,L2-1,GF,GF2" {
+		t.Fatalf("expected the grand final to be replayed but got %v", ids)
+	}
+
+	if matches[4].Players != [2]string{"carol", "bob"} || matches[4].Winner != "bob" {
+		t.Errorf("expected bob to drop into the losers final against carol but got %+v", matches[4])
+	}
+
+	champion, ok := tour.Champion()
+	if !ok || champion != "alice" {
+		t.Errorf("expected alice to win the replay but got %q", champion)
+	}
+
+	standings := tour.Standings()
+	var order []string
+	for _, s := range standings {
+		order = append(order, s.Handle)
+	}
+
+	if strings.Join(order, ",") != "alice,bob,carol,dave" {
+		t.Errorf("expected players to be ranked by how long they lasted but got %v", order)
+	}
+}
+
+func TestSwissPairsPlayersWithoutRematches(t *testing.T) {
+	t.Parallel()
+
+	tour, m := newTestTournament(t, tournamentRatings, []string{"alice", "bob", "carol", "dave", "erin"}, twittership.TournamentOptions{
+		Format: twittership.Swiss,
+	})
+
+	playTournament(t, m, tour, higherRated)
+
+	if !tour.Finished() {
+		t.Fatalf("expected the tournament to finish")
+	}
+
+	met := map[string]bool{}
+	byes := map[string]int{}
+	rounds := 0
+	for _, match := range tour.Matches() {
+		if match.Round > rounds {
+			rounds = match.Round
+		}
+
+		if match.Bye {
+			byes[match.Winner]++
+			continue
+		}
+
+		key := fmt.Sprint(match.Players)
+		if met[key] {
+			t.Errorf("expected no rematches but %v met twice", match.Players)
+		}
+		met[key] = true
+		met[fmt.Sprint([2]string{match.Players[1], match.Players[0]})] = true
+	}
+
+	if rounds != 3 {
+		t.Errorf("expected 3 rounds for 5 players but got %d", rounds)
+	}
+
+	for handle, n := range byes {
+		if n > 1 {
+			t.Errorf("expected %s to get at most one bye but got %d", handle, n)
+		}
+	}
+
+	standings := tour.Standings()
+	if standings[0].Handle != "alice" || standings[0].Points != 3 {
+		t.Errorf("expected alice to win every round but got %+v", standings[0])
+	}
+}
+
+func TestSwissAvoidsRematchesWhateverTheResults(t *testing.T) {
+	t.Parallel()
+
+	// After two rounds of six players a round without rematches always exists, but pairing
+	// each player with the next player they haven't played doesn't always find it
+	for seed := int64(1); seed <= 30; seed++ {
+		tour, m := newTestTournament(t, tournamentRatings, []string{"alice", "bob", "carol", "dave", "erin", "frank"}, twittership.TournamentOptions{
+			Format: twittership.Swiss,
+			Rounds: 3,
+		})
+
+		results := rand.New(rand.NewSource(seed))
+		playTournament(t, m, tour, func(game twittership.TournamentGame) string {
+			return game.Players[results.Intn(2)]
+		})
+
+		met := map[string]bool{}
+		for _, match := range tour.Matches() {
+			key := fmt.Sprint(match.Players)
+			if met[key] {
+				t.Errorf("seed %d: expected no rematches but %v met twice", seed, match.Players)
+			}
+			met[key] = true
+			met[fmt.Sprint([2]string{match.Players[1], match.Players[0]})] = true
+		}
+	}
+}
+
+func TestSwissPairsLateRoundsOfLargeFieldsQuickly(t *testing.T) {
+	t.Parallel()
+
+	// Late rounds of a large field leave few pairings without rematches, searching every
+	// one of them would take far longer than the limit
+	handles := make([]string, 41)
+	for i := range handles {
+		handles[i] = fmt.Sprintf("player%02d", i)
+	}
+
+	tour, m := newTestTournament(t, nil, handles, twittership.TournamentOptions{
+		Format: twittership.Swiss,
+		Rounds: 39,
+	})
+
+	results := rand.New(rand.NewSource(1))
+	for round := 1; ; round++ {
+		start := time.Now()
+		games, err := tour.Advance()
+		if err != nil {
+			t.Fatalf("advancing tournament: %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("expected round %d to be paired quickly but it took %v", round, elapsed)
+		}
+
+		if len(games) == 0 {
+			break
+		}
+
+		for _, game := range games {
+			loser := twittership.Side(results.Intn(2))
+			err := m.Do(game.GameID, func(g *twittership.Game) error {
+				return g.Forfeit(loser, twittership.Forfeited)
+			})
+			if err != nil {
+				t.Fatalf("finishing game %s: %v", game.GameID, err)
+			}
+		}
+	}
+
+	if !tour.Finished() {
+		t.Errorf("expected the tournament to finish")
+	}
+}
+
+func TestRoundRobinBreaksTiesAndDrawsTheStandings(t *testing.T) {
+	t.Parallel()
+
+	tour, m := newTestTournament(t, tournamentRatings, []string{"alice", "bob", "carol"}, twittership.TournamentOptions{
+		Name:   "Spring Cup",
+		Format: twittership.RoundRobin,
+	})
+
+	// Everyone finishes 1-1, alice beat bob who beat carol who beat alice
+	beats := map[string]string{"alice": "bob", "bob": "carol", "carol": "alice"}
+	playTournament(t, m, tour, func(game twittership.TournamentGame) string {
+		if beats[game.Players[0]] == game.Players[1] {
+			return game.Players[0]
+		}
+
+		return game.Players[1]
+	})
+
+	if matches := tour.Matches(); len(matches) != 3 {
+		t.Fatalf("expected every pair to play once but got %+v", matches)
+	}
+
+	standings := tour.Standings()
+	for _, s := range standings {
+		if s.Points != 1 || s.Buchholz != 2 || s.SonnebornBerger != 1 {
+			t.Errorf("expected every player to be tied but got %+v", s)
+		}
+	}
+
+	// The tiebreaks are level so the seeds decide
+	if standings[0].Handle != "alice" || standings[2].Handle != "carol" {
+		t.Errorf("expected the seeds to break the tie but got %+v", standings)
+	}
+
+	text := twittership.GetTournamentText(tour)
+	if !strings.Contains(text, "Spring Cup (Round Robin, best of 1)") || !strings.Contains(text, "(1) alice* 1-0 (2) bob") {
+		t.Errorf("expected the rounds and results in the text but got:\n%s", text)
+	}
+
+	ti, err := twittership.NewTournamentImage(tour, twittership.TournamentImageOptions{})
+	if err != nil {
+		t.Fatalf("creating tournament image: %v", err)
+	}
+
+	if size := ti.GetFullImage().Bounds().Size(); size.X != 600 || size.Y != 60+4*36 {
+		t.Errorf("expected a standings table with a row per player but got %s", size)
+	}
+}
+
+func TestTournamentImageDrawsTheBracket(t *testing.T) {
+	t.Parallel()
+
+	tour, m := newTestTournament(t, tournamentRatings, []string{"alice", "bob", "carol", "dave", "erin", "frank"}, twittership.TournamentOptions{
+		Format: twittership.DoubleElimination,
+	})
+	playTournament(t, m, tour, higherRated)
+
+	ti, err := twittership.NewTournamentImage(tour, twittership.TournamentImageOptions{Title: "Spring Cup"})
+	if err != nil {
+		t.Fatalf("creating tournament image: %v", err)
+	}
+
+	// Four losers rounds and the grand final follow the first round
+	img := ti.GetFullImage()
+	if size := img.Bounds().Size(); size.X < 6*170 || size.Y < 6*56 {
+		t.Errorf("expected the winners and losers brackets side by side but got %s", size)
+	}
+
+	// The title band also uses the header color so only the bracket below it is checked
+	highlighted := 0
+	for y := 60; y < img.Bounds().Max.Y; y++ {
+		for x := 0; x < img.Bounds().Max.X; x++ {
+			if img.RGBAAt(x, y) == twittership.ClassicTheme.Header {
+				highlighted++
+			}
+		}
+	}
+
+	if highlighted == 0 {
+		t.Errorf("expected the winners to be highlighted")
+	}
+}
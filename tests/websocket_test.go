@@ -0,0 +1,216 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"twittership"
+
+	"github.com/gorilla/websocket"
+)
+
+func dialLive(t *testing.T, serverURL, id, token string) *websocket.Conn {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(serverURL, "http") + "/games/" + id + "/ws"
+	if token != "" {
+		url += "?token=" + token
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dialing websocket: %v", err)
+	}
+
+	return conn
+}
+
+func readLiveEvent(t *testing.T, conn *websocket.Conn) twittership.LiveEvent {
+	t.Helper()
+
+	var event twittership.LiveEvent
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	err := conn.ReadJSON(&event)
+	if err != nil {
+		t.Fatalf("reading live event: %v", err)
+	}
+
+	return event
+}
+
+func TestLiveSpectatorsReceiveEventsForEveryVolley(t *testing.T) {
+	t.Parallel()
+
+	server, game := newTestServerGame(t)
+	defer server.Close()
+
+	spectator := dialLive(t, server.URL, game.ID, "")
+	defer spectator.Close()
+
+	player := dialLive(t, server.URL, game.ID, game.PlayerToken)
+	defer player.Close()
+
+	err := player.WriteJSON(map[string]string{"type": "volley", "position": "H8"})
+	if err != nil {
+		t.Fatalf("sending volley: %v", err)
+	}
+
+	for _, conn := range []*websocket.Conn{spectator, player} {
+		shot := readLiveEvent(t, conn)
+		if shot.Type != "shot" || shot.Move == nil || shot.Move.Position != "H8" {
+			t.Fatalf("expected a shot event at H8 but got %+v", shot)
+		}
+
+		result := readLiveEvent(t, conn)
+		if result.Type != "result" || result.Result != "Hit" {
+			t.Fatalf("expected a hit result event but got %+v", result)
+		}
+	}
+
+	res, body := doRequest(t, server, http.MethodPost, "/games/"+game.ID+"/volleys", game.EnemyToken, `{"position": "A1"}`)
+	expectStatus(t, res, body, http.StatusOK)
+
+	err = player.WriteJSON(map[string]string{"type": "volley", "position": "H9"})
+	if err != nil {
+		t.Fatalf("sending volley: %v", err)
+	}
+
+	expected := []string{"turn", "shot", "result", "turn", "shot", "result", "sunk"}
+	for _, eventType := range expected {
+		event := readLiveEvent(t, spectator)
+		if event.Type != eventType {
+			t.Fatalf("expected a %s event but got %+v", eventType, event)
+		}
+	}
+}
+
+func TestLiveSpectatorsAndPlayersOutOfTurnCannotAct(t *testing.T) {
+	t.Parallel()
+
+	server, game := newTestServerGame(t)
+	defer server.Close()
+
+	spectator := dialLive(t, server.URL, game.ID, "")
+	defer spectator.Close()
+
+	enemy := dialLive(t, server.URL, game.ID, game.EnemyToken)
+	defer enemy.Close()
+
+	for _, conn := range []*websocket.Conn{spectator, enemy} {
+		err := conn.WriteJSON(map[string]string{"type": "volley", "position": "A1"})
+		if err != nil {
+			t.Fatalf("sending volley: %v", err)
+		}
+
+		event := readLiveEvent(t, conn)
+		if event.Type != "error" {
+			t.Fatalf("expected an error event but got %+v", event)
+		}
+	}
+}
+
+func TestLivePlayersCannotFireSeveralShotsAtOnce(t *testing.T) {
+	t.Parallel()
+
+	server, game := newTestServerGame(t)
+	defer server.Close()
+
+	player := dialLive(t, server.URL, game.ID, game.PlayerToken)
+	defer player.Close()
+
+	err := player.WriteJSON(map[string]string{"type": "volley", "position": "A1;A2;A3;A4;A5"})
+	if err != nil {
+		t.Fatalf("sending volley: %v", err)
+	}
+
+	event := readLiveEvent(t, player)
+	if event.Type != "error" {
+		t.Fatalf("expected an error event but got %+v", event)
+	}
+}
+
+func TestLiveSlowClientsDoNotBlockTheGame(t *testing.T) {
+	t.Parallel()
+
+	server, game := newTestServerGame(t)
+	defer server.Close()
+
+	// This spectator never reads any events
+	slow := dialLive(t, server.URL, game.ID, "")
+	defer slow.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 10; x++ {
+				position := fmt.Sprintf(`{"position": "%c%d"}`, 'A'+y, x+1)
+				if position == `{"position": "H9"}` {
+					continue
+				}
+
+				for _, token := range []string{game.PlayerToken, game.EnemyToken} {
+					res, body := doRequest(t, server, http.MethodPost, "/games/"+game.ID+"/volleys", token, position)
+					if res.StatusCode != http.StatusOK {
+						t.Errorf("expected volley %s to succeed but got %d: %s", position, res.StatusCode, body)
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(20 * time.Second):
+		t.Fatalf("volleys were blocked by a slow client")
+	}
+}
+
+func TestLiveClientsAreDisconnectedWhenTheGameIsOver(t *testing.T) {
+	t.Parallel()
+
+	server, game := newTestServerGame(t)
+	defer server.Close()
+
+	// A spectator who leaves doesn't stop the next one from receiving events
+	first := dialLive(t, server.URL, game.ID, "")
+	first.Close()
+
+	spectator := dialLive(t, server.URL, game.ID, "")
+	defer spectator.Close()
+
+	path := "/games/" + game.ID + "/volleys"
+	for i, tile := range statsShipTiles {
+		res, body := doRequest(t, server, http.MethodPost, path, game.PlayerToken, `{"position": "`+tile+`"}`)
+		expectStatus(t, res, body, http.StatusOK)
+
+		if i < len(statsShipTiles)-1 {
+			res, body = doRequest(t, server, http.MethodPost, path, game.EnemyToken, `{"position": "`+statsMissTiles[i]+`"}`)
+			expectStatus(t, res, body, http.StatusOK)
+		}
+	}
+
+	for {
+		_ = spectator.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		var event twittership.LiveEvent
+		err := spectator.ReadJSON(&event)
+		if err != nil {
+			t.Fatalf("expected a gameOver event before being disconnected but got %v", err)
+		}
+
+		if event.Type == "gameOver" {
+			break
+		}
+	}
+
+	_ = spectator.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err := spectator.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseNoStatusReceived) {
+		t.Errorf("expected the spectator to be disconnected after the game but got %v", err)
+	}
+}
@@ -2,59 +2,341 @@ package twittership
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
 )
 
-func blueBg(message string) string {
-	return fmt.Sprintf("\x1b[44m%s\x1b[0m", message)
+// ColorMode selects when a TextRenderer uses ANSI colors.
+type ColorMode int
+
+const (
+	// ColorAuto uses colors when writing to a terminal and the NO_COLOR environment
+	// variable is not set.
+	ColorAuto ColorMode = iota
+	// ColorAlways always uses colors.
+	ColorAlways
+	// ColorNever never uses colors, misses and ships are drawn with letters instead.
+	ColorNever
+)
+
+// BorderStyle selects the characters a TextRenderer draws the boards with.
+type BorderStyle int
+
+const (
+	// BorderClassic is the original compact twittership layout.
+	BorderClassic BorderStyle = iota
+	// BorderASCII only uses ASCII characters so the output is safe in any log.
+	BorderASCII
+	// BorderUnicode uses box drawing characters.
+	BorderUnicode
+)
+
+// TextOptions configures a TextRenderer.
+type TextOptions struct {
+	Color   ColorMode
+	Borders BorderStyle
+	Boards  Boards
+	// RevealEnemyShips lifts the fog of war and shows every enemy ship, not only the
+	// ships that have been hit.
+	RevealEnemyShips bool
+	// Legend adds a line below the boards describing every tile.
+	Legend bool
+	// FleetStatus adds every ship of each board below it as afloat, damaged or sunk.
+	FleetStatus bool
 }
 
-func redBg(message string) string {
-	return fmt.Sprintf("\x1b[41m%s\x1b[0m", message)
+// TextRenderer draws a game as text which can be printed for a CLI version of twittership.
+type TextRenderer struct {
+	opts TextOptions
 }
 
-func getTileString(tile boardTile, isEnemyBoard bool) string {
-	// Ship with no volley
-	if tile.shipIndex != -1 && tile.volleyIndex == -1 && !isEnemyBoard {
-		return blueBg(" ")
+// NewTextRenderer creates a TextRenderer with the options provided.
+func NewTextRenderer(opts TextOptions) TextRenderer {
+	return TextRenderer{opts: opts}
+}
+
+// GetGameTextFromGame will return the game as text which can be printed for a CLI
+// version of twittership.
+func GetGameTextFromGame(g Game) string {
+	return NewTextRenderer(TextOptions{Color: ColorAlways}).Render(g)
+}
+
+// Render returns the game as text. ColorAuto checks whether os.Stdout is a terminal.
+func (r TextRenderer) Render(g Game) string {
+	return r.render(g, r.useColor(os.Stdout))
+}
+
+// Write writes the game as text to w. ColorAuto checks whether w is a terminal.
+func (r TextRenderer) Write(w io.Writer, g Game) error {
+	_, err := io.WriteString(w, r.render(g, r.useColor(w)))
+	if err != nil {
+		return fmt.Errorf("unable to write game text: %w", err)
 	}
 
-	// Hit volley
-	if tile.shipIndex != -1 && tile.volleyIndex != -1 {
-		return redBg("X")
+	return nil
+}
+
+func (r TextRenderer) useColor(w io.Writer) bool {
+	switch r.opts.Color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
 	}
 
-	// Missed volley
-	if tile.shipIndex == -1 && tile.volleyIndex != -1 {
-		return "X"
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
 	}
 
-	// Tile with no ship and no volley
-	return " "
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
-// GetGameTextFromGame will return the game as text which can be printed for a CLI
-// version of twittership.
-func GetGameTextFromGame(g Game) string {
-	output := "|---------------------------------------------|\n"
-	output += "|     PLAYER BOARD    | |     ENEMY BOARD     |\n"
-	output += "|---------------------------------------------|\n"
-	output += "|-|\u2488|\u2489|\u248A|\u248B|\u248C|\u248D|\u248E|\u248F|\u2490|\u2491| |-|\u2488|\u2489|\u248A|\u248B|\u248C|\u248D|\u248E|\u248F|\u2490|\u2491|\n"
+// textBoard is everything needed to draw one board.
+type textBoard struct {
+	title     string
+	fleet     string
+	tiles     [10][10]boardTile
+	ships     []ship
+	showShips bool
+	entries   []FleetEntry
+}
 
-	for y := 0; y < 10; y++ {
-		output += fmt.Sprintf("|%s", string('A'+y))
+// textLine is a single line of a board. Rules are horizontal lines which the classic layout
+// joins across both boards.
+type textLine struct {
+	text string
+	rule bool
+}
+
+func (r TextRenderer) render(g Game, color bool) string {
+	var boards []textBoard
+	if r.opts.Boards != EnemyBoardOnly {
+		boards = append(boards, textBoard{
+			title:     "PLAYER BOARD",
+			fleet:     "PLAYER FLEET",
+			tiles:     g.playerBoard,
+			ships:     g.playerShips,
+			showShips: true,
+			entries:   g.FleetStatus(PlayerSide, false),
+		})
+	}
+
+	if r.opts.Boards != PlayerBoardOnly {
+		boards = append(boards, textBoard{
+			title:     "ENEMY BOARD",
+			fleet:     "ENEMY FLEET",
+			tiles:     g.enemyBoard,
+			ships:     g.enemyShips,
+			showShips: r.opts.RevealEnemyShips,
+			entries:   g.FleetStatus(EnemySide, !r.opts.RevealEnemyShips),
+		})
+	}
+
+	blocks := make([][]textLine, len(boards))
+	for i, board := range boards {
+		if r.opts.Borders == BorderClassic {
+			blocks[i] = r.classicBoard(board, color)
+		} else {
+			blocks[i] = r.boxBoard(board, color)
+		}
+	}
+
+	var sb strings.Builder
+	for i := range blocks[0] {
+		line := blocks[0][i]
+		for _, block := range blocks[1:] {
+			line = joinTextLines(line, block[i], r.opts.Borders)
+		}
+
+		sb.WriteString(line.text)
+		sb.WriteString("\n")
+	}
+
+	if r.opts.Legend {
+		sb.WriteString(textLegend(color))
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// joinTextLines puts two board lines side by side. The classic layout joins rules into a
+// single line spanning both boards.
+func joinTextLines(left, right textLine, style BorderStyle) textLine {
+	if style != BorderClassic {
+		return textLine{text: left.text + "  " + right.text}
+	}
+
+	if left.rule && right.rule {
+		return textLine{text: strings.TrimSuffix(left.text, "|") + "---" + strings.TrimPrefix(right.text, "|"), rule: true}
+	}
+
+	return textLine{text: left.text + " " + right.text}
+}
+
+func (r TextRenderer) classicBoard(board textBoard, color bool) []textLine {
+	rule := textLine{text: "|" + strings.Repeat("-", 21) + "|", rule: true}
 
+	lines := []textLine{
+		rule,
+		{text: "|" + centerText(board.title, 21) + "|"},
+		rule,
+		{text: "|-|⒈|⒉|⒊|⒋|⒌|⒍|⒎|⒏|⒐|⒑|"},
+	}
+
+	for y := 0; y < 10; y++ {
+		row := fmt.Sprintf("|%c", 'A'+y)
 		for x := 0; x < 10; x++ {
-			output += fmt.Sprintf("|%s", getTileString(g.playerBoard[y][x], false))
+			row += "|" + getTileString(board.tiles[y][x], board.ships, board.showShips, color)
+		}
+
+		lines = append(lines, textLine{text: row + "|"})
+	}
+
+	if r.opts.FleetStatus {
+		lines = append(lines, rule, textLine{text: "|" + centerText(board.fleet, 21) + "|"}, rule)
+		// Every fleet takes the same number of lines so boards without ships still line up
+		for i := 0; i < fleetSize; i++ {
+			lines = append(lines, textLine{text: fmt.Sprintf("|%-21s|", fleetEntryText(board.entries, i))})
 		}
+	}
+
+	return lines
+}
+
+// boxCharacters are the characters used to draw a box: horizontal, vertical, then the
+// corners and joins from top left to bottom right.
+type boxCharacters struct {
+	h, v                                string
+	topLeft, topJoin, topRight          string
+	leftJoin, cross, rightJoin          string
+	bottomLeft, bottomJoin, bottomRight string
+}
 
-		output += fmt.Sprintf("| |%s", string('A'+y))
+var (
+	asciiBox   = boxCharacters{"-", "|", "+", "+", "+", "+", "+", "+", "+", "+", "+"}
+	unicodeBox = boxCharacters{"─", "│", "┌", "┬", "┐", "├", "┼", "┤", "└", "┴", "┘"}
+)
+
+func (r TextRenderer) boxBoard(board textBoard, color bool) []textLine {
+	b := asciiBox
+	if r.opts.Borders == BorderUnicode {
+		b = unicodeBox
+	}
+
+	// The grid is a space, two characters per column and another space
+	const labelWidth, gridWidth = 3, 22
+	full := strings.Repeat(b.h, labelWidth+1+gridWidth)
+	label, grid := strings.Repeat(b.h, labelWidth), strings.Repeat(b.h, gridWidth)
 
+	lines := []textLine{
+		{text: b.topLeft + full + b.topRight},
+		{text: b.v + centerText(board.title, labelWidth+1+gridWidth) + b.v},
+		{text: b.leftJoin + label + b.topJoin + grid + b.rightJoin},
+		{text: b.v + "   " + b.v + " 1 2 3 4 5 6 7 8 9 10 " + b.v},
+		{text: b.leftJoin + label + b.cross + grid + b.rightJoin},
+	}
+
+	for y := 0; y < 10; y++ {
+		row := fmt.Sprintf("%s %c %s ", b.v, 'A'+y, b.v)
 		for x := 0; x < 10; x++ {
-			output += fmt.Sprintf("|%s", getTileString(g.enemyBoard[y][x], true))
+			row += getTileString(board.tiles[y][x], board.ships, board.showShips, color) + " "
+		}
+
+		lines = append(lines, textLine{text: row + " " + b.v})
+	}
+
+	if !r.opts.FleetStatus {
+		return append(lines, textLine{text: b.bottomLeft + label + b.bottomJoin + grid + b.bottomRight})
+	}
+
+	lines = append(lines, textLine{text: b.leftJoin + label + b.bottomJoin + grid + b.rightJoin})
+	for i := 0; i < fleetSize; i++ {
+		entry := ""
+		if i < len(board.entries) {
+			entry = fmt.Sprintf("%-16s %-7s", board.entries[i].Name, board.entries[i].Status)
+		}
+
+		lines = append(lines, textLine{text: fmt.Sprintf("%s %-24s %s", b.v, entry, b.v)})
+	}
+
+	return append(lines, textLine{text: b.bottomLeft + full + b.bottomRight})
+}
+
+// centerText pads text with spaces to width, with any odd space on the left.
+func centerText(text string, width int) string {
+	space := width - utf8.RuneCountInString(text)
+	left := (space + 1) / 2
+
+	return strings.Repeat(" ", left) + text + strings.Repeat(" ", space-left)
+}
+
+func textLegend(color bool) string {
+	if color {
+		return fmt.Sprintf("Legend: %s ship  %s hit  X miss  %s sunk", blueBg(" "), redBg("X"), magentaBg("#"))
+	}
+
+	return "Legend: S ship  X hit  o miss  # sunk"
+}
+
+func blueBg(message string) string {
+	return fmt.Sprintf("\x1b[44m%s\x1b[0m", message)
+}
+
+func redBg(message string) string {
+	return fmt.Sprintf("\x1b[41m%s\x1b[0m", message)
+}
+
+func magentaBg(message string) string {
+	return fmt.Sprintf("\x1b[45m%s\x1b[0m", message)
+}
+
+func getTileString(tile boardTile, ships []ship, showShips bool, color bool) string {
+	// Sunk ship, shown on both boards
+	if tile.shipIndex != -1 && ships[tile.shipIndex].hits >= ships[tile.shipIndex].width {
+		if color {
+			return magentaBg("#")
+		}
+
+		return "#"
+	}
+
+	// Ship with no volley
+	if tile.shipIndex != -1 && tile.volleyIndex == -1 && showShips {
+		if color {
+			return blueBg(" ")
+		}
+
+		return "S"
+	}
+
+	// Hit volley
+	if tile.shipIndex != -1 && tile.volleyIndex != -1 {
+		if color {
+			return redBg("X")
+		}
+
+		return "X"
+	}
+
+	// Missed volley
+	if tile.shipIndex == -1 && tile.volleyIndex != -1 {
+		if color {
+			return "X"
 		}
 
-		output += "|\n"
+		return "o"
 	}
 
-	return output
+	// Tile with no ship and no volley
+	return " "
 }
@@ -0,0 +1,200 @@
+package twittership
+
+import (
+	"image/color"
+)
+
+// GlyphStyle is the shape drawn on a tile that a volley has been fired at.
+type GlyphStyle int
+
+const (
+	// GlyphCross draws an X across the tile.
+	GlyphCross GlyphStyle = iota
+	// GlyphDot draws a filled circle in the middle of the tile.
+	GlyphDot
+	// GlyphRing draws a circle outline in the middle of the tile.
+	GlyphRing
+	// GlyphNone only fills the tile with the hit or miss color.
+	GlyphNone
+)
+
+// Theme contains every color and style used to draw a GameImage.
+type Theme struct {
+	Name string
+
+	// Background fills the frame around the boards when no template is used.
+	Background color.RGBA
+	// Header fills the band behind the board titles when no template is used.
+	Header color.RGBA
+	// Label is used for the board titles and coordinate labels.
+	Label color.RGBA
+
+	Water color.RGBA
+	Grid  color.RGBA
+	Ship  color.RGBA
+	Hit   color.RGBA
+	Miss  color.RGBA
+	Sunk  color.RGBA
+	// Highlight outlines the most recent shot.
+	Highlight color.RGBA
+
+	// Glyph is the color of the shape drawn on top of a hit or miss.
+	Glyph     color.RGBA
+	HitGlyph  GlyphStyle
+	MissGlyph GlyphStyle
+	// LineWidth is the thickness of the glyph strokes in pixels.
+	LineWidth int
+	// GlyphPadding is the space in pixels between the glyph and the edges of the tile.
+	GlyphPadding int
+}
+
+// ClassicTheme is the original look of twittership and the theme used when none is given.
+var ClassicTheme = Theme{
+	Name:         "classic",
+	Background:   color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	Header:       color.RGBA{R: 209, G: 225, B: 249, A: 255},
+	Label:        color.RGBA{R: 0, G: 0, B: 0, A: 255},
+	Water:        color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	Grid:         color.RGBA{R: 0, G: 0, B: 0, A: 255},
+	Ship:         color.RGBA{R: 49, G: 83, B: 123, A: 255},
+	Hit:          color.RGBA{R: 255, G: 54, B: 51, A: 255},
+	Miss:         color.RGBA{R: 200, G: 200, B: 200, A: 255},
+	Sunk:         color.RGBA{R: 122, G: 16, B: 16, A: 255},
+	Highlight:    color.RGBA{R: 255, G: 170, B: 0, A: 255},
+	Glyph:        color.RGBA{R: 100, G: 100, B: 100, A: 255},
+	HitGlyph:     GlyphCross,
+	MissGlyph:    GlyphCross,
+	LineWidth:    3,
+	GlyphPadding: 5,
+}
+
+// DarkTheme is a low glare theme for dark mode.
+var DarkTheme = Theme{
+	Name:         "dark",
+	Background:   color.RGBA{R: 24, G: 26, B: 32, A: 255},
+	Header:       color.RGBA{R: 40, G: 44, B: 52, A: 255},
+	Label:        color.RGBA{R: 220, G: 223, B: 228, A: 255},
+	Water:        color.RGBA{R: 30, G: 41, B: 59, A: 255},
+	Grid:         color.RGBA{R: 71, G: 85, B: 105, A: 255},
+	Ship:         color.RGBA{R: 100, G: 116, B: 139, A: 255},
+	Hit:          color.RGBA{R: 220, G: 38, B: 38, A: 255},
+	Miss:         color.RGBA{R: 51, G: 65, B: 85, A: 255},
+	Sunk:         color.RGBA{R: 127, G: 29, B: 29, A: 255},
+	Highlight:    color.RGBA{R: 250, G: 204, B: 21, A: 255},
+	Glyph:        color.RGBA{R: 241, G: 245, B: 249, A: 255},
+	HitGlyph:     GlyphCross,
+	MissGlyph:    GlyphDot,
+	LineWidth:    3,
+	GlyphPadding: 5,
+}
+
+// ColorBlindTheme uses the Okabe-Ito palette, which stays distinguishable with every
+// common form of color blindness, and different glyphs for hits and misses so the
+// board can be read without relying on color at all.
+var ColorBlindTheme = Theme{
+	Name:         "colorblind",
+	Background:   color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	Header:       color.RGBA{R: 86, G: 180, B: 233, A: 255},
+	Label:        color.RGBA{R: 0, G: 0, B: 0, A: 255},
+	Water:        color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	Grid:         color.RGBA{R: 0, G: 0, B: 0, A: 255},
+	Ship:         color.RGBA{R: 0, G: 114, B: 178, A: 255},
+	Hit:          color.RGBA{R: 213, G: 94, B: 0, A: 255},
+	Miss:         color.RGBA{R: 240, G: 228, B: 66, A: 255},
+	Sunk:         color.RGBA{R: 204, G: 121, B: 167, A: 255},
+	Highlight:    color.RGBA{R: 0, G: 158, B: 115, A: 255},
+	Glyph:        color.RGBA{R: 0, G: 0, B: 0, A: 255},
+	HitGlyph:     GlyphCross,
+	MissGlyph:    GlyphRing,
+	LineWidth:    3,
+	GlyphPadding: 5,
+}
+
+// HighContrastTheme uses pure black, white and yellow for low vision readers.
+var HighContrastTheme = Theme{
+	Name:         "highcontrast",
+	Background:   color.RGBA{R: 0, G: 0, B: 0, A: 255},
+	Header:       color.RGBA{R: 0, G: 0, B: 0, A: 255},
+	Label:        color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	Water:        color.RGBA{R: 0, G: 0, B: 0, A: 255},
+	Grid:         color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	Ship:         color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	Hit:          color.RGBA{R: 255, G: 221, B: 0, A: 255},
+	Miss:         color.RGBA{R: 0, G: 0, B: 0, A: 255},
+	Sunk:         color.RGBA{R: 255, G: 0, B: 0, A: 255},
+	Highlight:    color.RGBA{R: 0, G: 255, B: 255, A: 255},
+	Glyph:        color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	HitGlyph:     GlyphCross,
+	MissGlyph:    GlyphDot,
+	LineWidth:    4,
+	GlyphPadding: 6,
+}
+
+// Themes returns every built in theme.
+func Themes() []Theme {
+	return []Theme{ClassicTheme, DarkTheme, ColorBlindTheme, HighContrastTheme}
+}
+
+// ThemeByName returns the built in theme with the name provided.
+func ThemeByName(name string) (Theme, bool) {
+	for _, t := range Themes() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+
+	return Theme{}, false
+}
+
+// isPointOnGlyph determines if the pixel at x, y within a tile of xWidth by yWidth
+// is part of the glyph and needs to be filled in.
+func (t Theme) isPointOnGlyph(style GlyphStyle, x, y, xWidth, yWidth int) bool {
+	switch style {
+	case GlyphCross:
+		return t.isPointOnX(x, y, xWidth, yWidth)
+	case GlyphDot, GlyphRing:
+		radius := xWidth
+		if yWidth < radius {
+			radius = yWidth
+		}
+		radius = radius/2 - t.GlyphPadding
+
+		dx, dy := 2*x-xWidth, 2*y-yWidth
+		distance := dx*dx + dy*dy
+		if distance > 4*radius*radius {
+			return false
+		}
+
+		inner := radius - t.LineWidth
+		return style == GlyphDot || inner < 0 || distance >= 4*inner*inner
+	default:
+		return false
+	}
+}
+
+// isPointOnX is used when drawing the X image on the game board to indicate a volley.
+// It will determine the the current pixel is anywhere on the X and need to be filled in.
+func (t Theme) isPointOnX(x, y, xWidth, yWidth int) bool {
+	thickness := t.LineWidth
+
+	// padding around the X axis on the X
+	if x < t.GlyphPadding || x > xWidth-t.GlyphPadding {
+		return false
+	}
+
+	// padding around the Y axis on the Y
+	if y < t.GlyphPadding || y > yWidth-t.GlyphPadding {
+		return false
+	}
+
+	// Is the point on the X. The diagonals are scaled so they still meet the corners of tiles
+	// that aren't square, for square tiles this is the distance from the diagonal in pixels.
+	diagonal := x*yWidth - y*xWidth
+	antiDiagonal := x*yWidth - (yWidth-y)*xWidth
+	limit := thickness * (xWidth + yWidth) / 2
+	if (diagonal > -limit && diagonal < limit) || (antiDiagonal > -limit && antiDiagonal < limit) {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,219 @@
+package twittership
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Clock tells the manager what time it is. It can be replaced in tests so turn
+// deadlines can be checked without waiting for them.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// TimeoutPolicy configures how long a side has to act before it forfeits the game.
+type TimeoutPolicy struct {
+	// TurnDeadline is how long a side has to act once it is their turn. A zero
+	// TurnDeadline disables timeouts.
+	TurnDeadline time.Duration
+	// Reminders are the amounts of time left before the deadline at which a reminder
+	// is sent, I.E. 12h and 1h.
+	Reminders []time.Duration
+}
+
+// TimeoutNotice is delivered when a reminder threshold passes or when a game is
+// forfeited because the deadline passed.
+type TimeoutNotice struct {
+	GameID    string
+	Side      Side
+	Deadline  time.Time
+	Remaining time.Duration
+	Forfeit   bool
+}
+
+// SetClock replaces the clock used to track turn deadlines.
+func (m *Manager) SetClock(clock Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clock = clock
+}
+
+// SetTimeoutPolicy configures turn deadlines for every game in the manager. The notify
+// function is called for every reminder and forfeit, it is never called while a game
+// is locked so it may safely use the manager.
+func (m *Manager) SetTimeoutPolicy(policy TimeoutPolicy, notify func(TimeoutNotice)) {
+	reminders := append([]time.Duration(nil), policy.Reminders...)
+	sort.Slice(reminders, func(i, j int) bool {
+		return reminders[i] > reminders[j]
+	})
+	policy.Reminders = reminders
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.policy = policy
+	m.notify = notify
+}
+
+// Deadline returns the time by which the side whose turn it is must act in the game
+// with the id provided. The second return value is false if timeouts are disabled or
+// the game is finished.
+func (m *Manager) Deadline(id string) (time.Time, bool, error) {
+	policy := m.timeoutPolicy()
+
+	mg, err := m.lookup(id)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	mg.mu.Lock()
+	defer mg.mu.Unlock()
+
+	if policy.TurnDeadline == 0 || mg.game.IsFinished() {
+		return time.Time{}, false, nil
+	}
+
+	return mg.turnStarted.Add(policy.TurnDeadline), true, nil
+}
+
+// CheckTimeouts sends any reminders that are due and forfeits every game where the
+// side whose turn it is has passed the turn deadline. Games whose statistics couldn't be
+// recorded before are retried.
+func (m *Manager) CheckTimeouts() {
+	m.RetryStats()
+
+	policy := m.timeoutPolicy()
+	if policy.TurnDeadline == 0 {
+		return
+	}
+
+	m.mu.RLock()
+	games := make(map[string]*managedGame, len(m.games))
+	for id, mg := range m.games {
+		games[id] = mg
+	}
+	notify := m.notify
+	m.mu.RUnlock()
+
+	var notices []TimeoutNotice
+	var forfeited []finishedGame
+	for id, mg := range games {
+		notice, ok := m.checkTimeout(id, mg, policy)
+		if ok {
+			notices = append(notices, notice)
+		}
+
+		if notice.Forfeit {
+			mg.mu.Lock()
+			finished, record := m.claimStats(id, mg)
+			mg.mu.Unlock()
+
+			if record {
+				forfeited = append(forfeited, finished)
+			}
+		}
+	}
+
+	m.recordStats(forfeited...)
+
+	if notify == nil {
+		return
+	}
+
+	sort.Slice(notices, func(i, j int) bool {
+		return notices[i].GameID < notices[j].GameID
+	})
+
+	for _, notice := range notices {
+		notify(notice)
+	}
+}
+
+// RunTimeouts checks for timeouts every interval until the context is cancelled.
+func (m *Manager) RunTimeouts(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.CheckTimeouts()
+		}
+	}
+}
+
+func (m *Manager) checkTimeout(id string, mg *managedGame, policy TimeoutPolicy) (TimeoutNotice, bool) {
+	mg.mu.Lock()
+	defer mg.mu.Unlock()
+
+	if mg.game.IsFinished() {
+		return TimeoutNotice{}, false
+	}
+
+	deadline := mg.turnStarted.Add(policy.TurnDeadline)
+	remaining := deadline.Sub(m.now())
+	notice := TimeoutNotice{
+		GameID:    id,
+		Side:      mg.game.Turn(),
+		Deadline:  deadline,
+		Remaining: remaining,
+	}
+
+	if remaining <= 0 {
+		// Forfeit can't fail here as the game was checked to not be finished above
+		_ = mg.game.Forfeit(notice.Side, TimedOut)
+		notice.Remaining = 0
+		notice.Forfeit = true
+
+		return notice, true
+	}
+
+	// Only the most urgent reminder is sent if several thresholds passed at once
+	due := false
+	for mg.remindersSent < len(policy.Reminders) && remaining <= policy.Reminders[mg.remindersSent] {
+		mg.remindersSent++
+		due = true
+	}
+
+	return notice, due
+}
+
+func (m *Manager) timeoutPolicy() TimeoutPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.policy
+}
+
+func (m *Manager) now() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.clock.Now()
+}
+
+// gameProgress counts every action taken in a game so the manager can tell when a
+// side has acted and their turn deadline should be reset.
+func gameProgress(g Game) int {
+	progress := len(g.playerVolleys) + len(g.enemyVolleys)
+
+	if len(g.playerShips) > 0 {
+		progress++
+	}
+
+	if len(g.enemyShips) > 0 {
+		progress++
+	}
+
+	return progress
+}
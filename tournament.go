@@ -0,0 +1,914 @@
+package twittership
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// ErrTooFewEntrants is returned when creating a tournament with fewer than two players.
+var ErrTooFewEntrants = errors.New("a tournament needs at least two players")
+
+// ErrDuplicateEntrant is returned when a player enters a tournament twice.
+var ErrDuplicateEntrant = errors.New("player entered twice")
+
+// ErrInvalidBestOf is returned when a match length isn't a positive odd number of games.
+var ErrInvalidBestOf = errors.New("best of must be a positive odd number")
+
+// TournamentFormat selects how players are paired in a tournament.
+type TournamentFormat int
+
+const (
+	// SingleElimination knocks out a player after their first lost match.
+	SingleElimination TournamentFormat = iota
+	// DoubleElimination knocks out a player after their second lost match. Players who lose
+	// once drop into the losers bracket, whose winner meets the winner of the winners
+	// bracket in the grand final. The grand final is replayed if the losers bracket player
+	// wins it, as until then they have only lost once.
+	DoubleElimination
+	// Swiss plays a fixed number of rounds, each pairing players with similar scores who
+	// haven't met yet.
+	Swiss
+	// RoundRobin plays every player against every other player once.
+	RoundRobin
+)
+
+func (f TournamentFormat) String() string {
+	names := [...]string{"Single Elimination", "Double Elimination", "Swiss", "Round Robin"}
+	if f < 0 || int(f) >= len(names) {
+		return fmt.Sprintf("TournamentFormat(%d)", f)
+	}
+
+	return names[f]
+}
+
+// TournamentBracket identifies which part of an elimination tournament a match is in.
+type TournamentBracket int
+
+const (
+	// WinnersBracket holds every match of single elimination, Swiss and round robin
+	// tournaments, and the matches of players who haven't lost in double elimination.
+	WinnersBracket TournamentBracket = iota
+	// LosersBracket holds the matches of players who have lost once in double elimination.
+	LosersBracket
+	// GrandFinal holds the final of double elimination and its replay.
+	GrandFinal
+)
+
+// TournamentOptions configures a tournament.
+type TournamentOptions struct {
+	Name   string
+	Format TournamentFormat
+	// BestOf is how many games a match is played over, the first player to win more than
+	// half of them wins the match. It defaults to 1.
+	BestOf int
+	// Rounds is how many rounds a Swiss tournament plays, it defaults to enough rounds for a
+	// single player to win every match.
+	Rounds int
+	// Unrated games don't change the ratings of the players.
+	Unrated bool
+}
+
+// Entrant is a player in a tournament. Seed 1 is the highest rated player.
+type Entrant struct {
+	Handle string  `json:"handle"`
+	Seed   int     `json:"seed"`
+	Rating float64 `json:"rating"`
+}
+
+// TournamentMatch is a match between two players in a tournament. A player that isn't
+// known yet has an empty handle and a seed of zero.
+type TournamentMatch struct {
+	ID      string            `json:"id"`
+	Bracket TournamentBracket `json:"bracket"`
+	Round   int               `json:"round"`
+	Players [2]string         `json:"players"`
+	Seeds   [2]int            `json:"seeds"`
+	// Wins is the number of games each player has won.
+	Wins    [2]int   `json:"wins"`
+	GameIDs []string `json:"gameIds"`
+	// Winner is set once the match is decided.
+	Winner string `json:"winner,omitempty"`
+	// Bye is true when the match was decided without being played as a player had no
+	// opponent.
+	Bye bool `json:"bye"`
+}
+
+// TournamentGame is a game that was created for a tournament match.
+type TournamentGame struct {
+	MatchID string
+	GameID  string
+	Players Players
+	// Tokens are the secrets each side acts in the game with through the Server, indexed
+	// like Players.
+	Tokens [2]string
+}
+
+// matchSlot is one of the two places in a match that a player fills.
+type matchSlot struct {
+	match int
+	slot  int
+}
+
+type tournamentMatch struct {
+	TournamentMatch
+	// filled is true once the player of a slot is known, a filled slot without a handle
+	// is a bye
+	filled [2]bool
+	done   bool
+	// current is the game being played
+	current  string
+	winnerTo *matchSlot
+	loserTo  *matchSlot
+	// stage orders the matches that knock players out, players knocked out at a later
+	// stage finish higher
+	stage int
+}
+
+func (m tournamentMatch) ready() bool {
+	return !m.done && m.filled[0] && m.filled[1]
+}
+
+// Tournament runs the matches of a tournament as games in a manager. Call Advance to create
+// the games of every match that is ready to be played and to record the games that have
+// finished.
+type Tournament struct {
+	mu       sync.Mutex
+	manager  *Manager
+	id       string
+	opts     TournamentOptions
+	entrants []Entrant
+	matches  []tournamentMatch
+	// rounds is the number of rounds of a Swiss or round robin tournament
+	rounds int
+}
+
+// NewTournament seeds the players by rating and builds the first round of matches. Ratings
+// are read from the statistics of the manager, players with equal ratings are seeded in the
+// order provided. Game ids start with id.
+func NewTournament(m *Manager, id string, handles []string, opts TournamentOptions) (*Tournament, error) {
+	if len(handles) < 2 {
+		return nil, ErrTooFewEntrants
+	}
+
+	if opts.BestOf == 0 {
+		opts.BestOf = 1
+	}
+
+	if opts.BestOf < 0 || opts.BestOf%2 == 0 {
+		return nil, fmt.Errorf("best of %d: %w", opts.BestOf, ErrInvalidBestOf)
+	}
+
+	if opts.Name == "" {
+		opts.Name = id
+	}
+
+	entrants := make([]Entrant, 0, len(handles))
+	seen := map[string]bool{}
+	for _, handle := range handles {
+		if seen[handleKey(handle)] {
+			return nil, fmt.Errorf("creating tournament %s: %s: %w", id, handle, ErrDuplicateEntrant)
+		}
+		seen[handleKey(handle)] = true
+
+		rating, err := m.playerRating(handle)
+		if err != nil {
+			return nil, fmt.Errorf("creating tournament %s: %w", id, err)
+		}
+
+		entrants = append(entrants, Entrant{Handle: handle, Rating: rating})
+	}
+
+	sort.SliceStable(entrants, func(i, j int) bool {
+		return entrants[i].Rating > entrants[j].Rating
+	})
+
+	for i := range entrants {
+		entrants[i].Seed = i + 1
+	}
+
+	t := &Tournament{manager: m, id: id, opts: opts, entrants: entrants}
+
+	switch opts.Format {
+	case SingleElimination, DoubleElimination:
+		t.buildElimination()
+	case Swiss:
+		t.rounds = opts.Rounds
+		if t.rounds == 0 {
+			t.rounds = int(math.Ceil(math.Log2(float64(len(entrants)))))
+		}
+
+		t.pairSwissRound(1)
+	case RoundRobin:
+		t.buildRoundRobin()
+	default:
+		return nil, fmt.Errorf("creating tournament %s: unknown format %d", id, opts.Format)
+	}
+
+	t.resolveByes()
+
+	return t, nil
+}
+
+// seedOrder returns the seeds in bracket order for a bracket of size players, so the top
+// seeds can only meet in the later rounds I.E. 1, 8, 4, 5, 2, 7, 3, 6.
+func seedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, seed := range order {
+			next = append(next, seed, len(order)*2+1-seed)
+		}
+		order = next
+	}
+
+	return order
+}
+
+// buildElimination creates every match of the bracket. The bracket is filled up to a power
+// of two with byes, which the top seeds get.
+func (t *Tournament) buildElimination() {
+	size := 2
+	for size < len(t.entrants) {
+		size *= 2
+	}
+
+	rounds := int(math.Log2(float64(size)))
+
+	// winners[r][i] is the index of match i of winners round r+1
+	winners := make([][]int, rounds)
+	for r := 0; r < rounds; r++ {
+		for i := 0; i < size>>(r+1); i++ {
+			winners[r] = append(winners[r], t.addMatch(WinnersBracket, r+1, i, r+1))
+		}
+	}
+
+	for r := 0; r < rounds-1; r++ {
+		for i, m := range winners[r] {
+			t.matches[m].winnerTo = &matchSlot{winners[r+1][i/2], i % 2}
+		}
+	}
+
+	for i, seed := range seedOrder(size) {
+		t.fill(matchSlot{winners[0][i/2], i % 2}, t.entrant(seed))
+	}
+
+	if t.opts.Format == SingleElimination {
+		return
+	}
+
+	// Players never leave the winners bracket in double elimination
+	for r := range winners {
+		for _, m := range winners[r] {
+			t.matches[m].stage = 0
+		}
+	}
+
+	// Odd losers rounds pair the players already in the losers bracket, even losers rounds
+	// bring in the players who lost the next winners round
+	var losers [][]int
+	for r := 0; r < 2*(rounds-1); r++ {
+		count := size >> (r/2 + 2)
+		var round []int
+		for i := 0; i < count; i++ {
+			round = append(round, t.addMatch(LosersBracket, r+1, i, r+1))
+		}
+		losers = append(losers, round)
+	}
+
+	final := t.addMatch(GrandFinal, 1, 0, len(losers)+1)
+	t.matches[final].ID = "GF"
+	t.matches[winners[rounds-1][0]].winnerTo = &matchSlot{final, 0}
+
+	if len(losers) == 0 {
+		t.matches[winners[0][0]].loserTo = &matchSlot{final, 1}
+		return
+	}
+
+	for i, m := range winners[0] {
+		t.matches[m].loserTo = &matchSlot{losers[0][i/2], i % 2}
+	}
+
+	for r, round := range losers {
+		for i, m := range round {
+			switch {
+			case r == len(losers)-1:
+				t.matches[m].winnerTo = &matchSlot{final, 1}
+			case r%2 == 0:
+				// The next round has the same number of matches and adds a winners round loser
+				t.matches[m].winnerTo = &matchSlot{losers[r+1][i], 0}
+			default:
+				t.matches[m].winnerTo = &matchSlot{losers[r+1][i/2], i % 2}
+			}
+		}
+
+		if r%2 == 1 {
+			// Losers of winners round r/2+2 join in reverse order so players who met in the
+			// winners bracket don't meet again straight away
+			from := winners[r/2+1]
+			for i, m := range from {
+				t.matches[m].loserTo = &matchSlot{round[len(round)-1-i], 1}
+			}
+		}
+	}
+}
+
+// addMatch appends a match and returns its index.
+func (t *Tournament) addMatch(bracket TournamentBracket, round, i, stage int) int {
+	prefix := map[TournamentBracket]string{WinnersBracket: "R", LosersBracket: "L", GrandFinal: "GF"}[bracket]
+	if t.opts.Format == DoubleElimination && bracket == WinnersBracket {
+		prefix = "W"
+	}
+
+	t.matches = append(t.matches, tournamentMatch{
+		TournamentMatch: TournamentMatch{
+			ID:      fmt.Sprintf("%s%d-%d", prefix, round, i+1),
+			Bracket: bracket,
+			Round:   round,
+		},
+		stage: stage,
+	})
+
+	return len(t.matches) - 1
+}
+
+// entrant returns the entrant with seed, seeds past the last entrant are byes.
+func (t *Tournament) entrant(seed int) Entrant {
+	if seed > len(t.entrants) {
+		return Entrant{}
+	}
+
+	return t.entrants[seed-1]
+}
+
+func (t *Tournament) fill(slot matchSlot, e Entrant) {
+	m := &t.matches[slot.match]
+	m.Players[slot.slot] = e.Handle
+	m.Seeds[slot.slot] = e.Seed
+	m.filled[slot.slot] = true
+}
+
+// buildRoundRobin schedules every round with the circle method, with a rest for one player
+// each round when there is an odd number of players.
+func (t *Tournament) buildRoundRobin() {
+	players := append([]Entrant(nil), t.entrants...)
+	if len(players)%2 == 1 {
+		players = append(players, Entrant{})
+	}
+
+	t.rounds = len(players) - 1
+	for r := 0; r < t.rounds; r++ {
+		i := 0
+		for p := 0; p < len(players)/2; p++ {
+			a, b := players[p], players[len(players)-1-p]
+			if a.Handle == "" || b.Handle == "" {
+				continue
+			}
+
+			// The higher seed plays first in the first game
+			if b.Seed < a.Seed {
+				a, b = b, a
+			}
+
+			m := t.addMatch(WinnersBracket, r+1, i, r+1)
+			t.fill(matchSlot{m, 0}, a)
+			t.fill(matchSlot{m, 1}, b)
+			i++
+		}
+
+		// Every player but the first moves one place around the circle
+		last := players[len(players)-1]
+		copy(players[2:], players[1:len(players)-1])
+		players[1] = last
+	}
+}
+
+// pairSwissRound pairs the players for a round of a Swiss tournament. The first round pairs
+// the top half of the seeds with the bottom half, later rounds pair players in standings
+// order with the closest player they haven't played, see swissPairs. With an odd number of
+// players the lowest player who hasn't had a bye gets one, which counts as a win.
+func (t *Tournament) pairSwissRound(round int) {
+	var players []Entrant
+	if round == 1 {
+		players = append(players, t.entrants...)
+	} else {
+		for _, s := range t.standings() {
+			players = append(players, t.entrant(s.Seed))
+		}
+	}
+
+	played := map[[2]string]bool{}
+	byes := map[string]bool{}
+	for _, m := range t.matches {
+		if m.Bye {
+			byes[m.Players[0]] = true
+			continue
+		}
+
+		played[[2]string{m.Players[0], m.Players[1]}] = true
+		played[[2]string{m.Players[1], m.Players[0]}] = true
+	}
+
+	i := 0
+	pairs, bye := swissPairs(players, played, byes, round == 1)
+	if bye != nil {
+		m := t.addMatch(WinnersBracket, round, i, round)
+		t.fill(matchSlot{m, 0}, *bye)
+		t.fill(matchSlot{m, 1}, Entrant{})
+		i++
+	}
+
+	for _, pair := range pairs {
+		m := t.addMatch(WinnersBracket, round, i, round)
+		t.fill(matchSlot{m, 0}, pair[0])
+		t.fill(matchSlot{m, 1}, pair[1])
+		i++
+	}
+}
+
+// swissPairingSteps is how many pairings swissPairs tries before it allows rematches.
+const swissPairingSteps = 100000
+
+// swissPairs pairs the players, who are in standings order. The first round pairs the top
+// half against the bottom half. Later rounds pair each player with the closest player in
+// the standings they haven't played, backtracking whenever that leaves players who can't
+// be paired, so rematches only happen when every pairing of the round needs one. The search
+// gives up after swissPairingSteps pairings have been tried, which only happens in late
+// rounds of large fields, and then allows rematches. With an odd number of players the
+// lowest player without a bye sits out, or the lowest player if every player has had one.
+func swissPairs(players []Entrant, played map[[2]string]bool, byes map[string]bool, first bool) ([][2]Entrant, *Entrant) {
+	var candidates []int
+	if len(players)%2 == 1 {
+		for p := len(players) - 1; p >= 0; p-- {
+			if !byes[players[p].Handle] {
+				candidates = append(candidates, p)
+			}
+		}
+
+		if len(candidates) == 0 {
+			candidates = []int{len(players) - 1}
+		}
+	} else {
+		candidates = []int{-1}
+	}
+
+	without := func(p int) []Entrant {
+		if p < 0 {
+			return players
+		}
+
+		rest := append([]Entrant(nil), players[:p]...)
+		return append(rest, players[p+1:]...)
+	}
+
+	byeFor := func(p int) *Entrant {
+		if p < 0 {
+			return nil
+		}
+
+		return &players[p]
+	}
+
+	if first {
+		rest := without(candidates[0])
+		half := len(rest) / 2
+
+		var pairs [][2]Entrant
+		for p := 0; p < half; p++ {
+			pairs = append(pairs, [2]Entrant{rest[p], rest[p+half]})
+		}
+
+		return pairs, byeFor(candidates[0])
+	}
+
+	steps := swissPairingSteps
+	for _, bye := range candidates {
+		if pairs, ok := pairWithoutRematches(without(bye), played, &steps); ok {
+			return pairs, byeFor(bye)
+		}
+	}
+
+	// Every pairing needs a rematch, or finding one that doesn't took too long, so each
+	// player takes the closest player they haven't played or the next player once they
+	// have played everybody left
+	rest := without(candidates[0])
+
+	var pairs [][2]Entrant
+	for len(rest) > 0 {
+		opponent := 1
+		for o := 1; o < len(rest); o++ {
+			if !played[[2]string{rest[0].Handle, rest[o].Handle}] {
+				opponent = o
+				break
+			}
+		}
+
+		pairs = append(pairs, [2]Entrant{rest[0], rest[opponent]})
+		rest = append(rest[1:opponent], rest[opponent+1:]...)
+	}
+
+	return pairs, byeFor(candidates[0])
+}
+
+// pairWithoutRematches pairs the highest player with the closest player they haven't
+// played and pairs the rest the same way, trying the next closest player whenever the rest
+// can't be paired. Every pairing tried uses up one of the steps left. ok is false if there
+// is no pairing without a rematch or the steps ran out first.
+func pairWithoutRematches(players []Entrant, played map[[2]string]bool, steps *int) ([][2]Entrant, bool) {
+	if len(players) == 0 {
+		return nil, true
+	}
+
+	for o := 1; o < len(players); o++ {
+		if played[[2]string{players[0].Handle, players[o].Handle}] {
+			continue
+		}
+
+		if *steps <= 0 {
+			return nil, false
+		}
+		*steps--
+
+		rest := append([]Entrant(nil), players[1:o]...)
+		rest = append(rest, players[o+1:]...)
+
+		pairs, ok := pairWithoutRematches(rest, played, steps)
+		if ok {
+			return append([][2]Entrant{{players[0], players[o]}}, pairs...), true
+		}
+	}
+
+	return nil, false
+}
+
+// resolveByes decides every match that has a bye, which may fill the next matches.
+func (t *Tournament) resolveByes() {
+	for changed := true; changed; {
+		changed = false
+		for i := range t.matches {
+			m := &t.matches[i]
+			if !m.ready() || (m.Players[0] != "" && m.Players[1] != "") {
+				continue
+			}
+
+			winner := 0
+			if m.Players[0] == "" {
+				winner = 1
+			}
+
+			m.Bye = true
+			t.decide(i, winner)
+			changed = true
+		}
+	}
+}
+
+// decide records the winner of a match and moves both players on.
+func (t *Tournament) decide(i, winner int) {
+	m := &t.matches[i]
+	m.done = true
+	m.Winner = m.Players[winner]
+
+	seed := func(slot int) Entrant {
+		return Entrant{Handle: m.Players[slot], Seed: m.Seeds[slot]}
+	}
+
+	if m.winnerTo != nil {
+		t.fill(*m.winnerTo, seed(winner))
+	}
+
+	if m.loserTo != nil {
+		t.fill(*m.loserTo, seed(1-winner))
+	}
+
+	// The losers bracket player has only lost once if they win the grand final
+	if m.Bracket == GrandFinal && m.Round == 1 && winner == 1 && !m.Bye {
+		reset := t.addMatch(GrandFinal, 2, 0, m.stage+1)
+		t.matches[reset].ID = "GF2"
+		t.fill(matchSlot{reset, 0}, seed(0))
+		t.fill(matchSlot{reset, 1}, seed(1))
+	}
+}
+
+// currentRound returns the earliest round of a Swiss or round robin tournament that still
+// has matches to play.
+func (t *Tournament) currentRound() int {
+	round := t.rounds + 1
+	for _, m := range t.matches {
+		if !m.done && m.Round < round {
+			round = m.Round
+		}
+	}
+
+	return round
+}
+
+// Advance records the result of every tournament game that has finished, decides the
+// matches that have been won and creates the next game of every match that is ready. It
+// returns the games it created.
+func (t *Tournament) Advance() ([]TournamentGame, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range t.matches {
+		err := t.recordGame(i)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t.resolveByes()
+
+	// The next Swiss round is paired once every match of the last round is done
+	if last := t.lastRound(); t.opts.Format == Swiss && t.currentRound() > last && last < t.rounds {
+		t.pairSwissRound(last + 1)
+		t.resolveByes()
+	}
+
+	round := t.currentRound()
+
+	var created []TournamentGame
+	for i := range t.matches {
+		m := &t.matches[i]
+		if !m.ready() || m.current != "" {
+			continue
+		}
+
+		// Swiss and round robin tournaments play one round at a time
+		if (t.opts.Format == Swiss || t.opts.Format == RoundRobin) && m.Round != round {
+			continue
+		}
+
+		game, err := t.createGame(i)
+		if err != nil {
+			return created, err
+		}
+
+		created = append(created, game)
+	}
+
+	return created, nil
+}
+
+func (t *Tournament) lastRound() int {
+	last := 0
+	for _, m := range t.matches {
+		if m.Round > last {
+			last = m.Round
+		}
+	}
+
+	return last
+}
+
+// recordGame adds the result of the current game of a match once it has finished.
+func (t *Tournament) recordGame(i int) error {
+	m := &t.matches[i]
+	if m.current == "" {
+		return nil
+	}
+
+	g, err := t.manager.Get(m.current)
+	if err != nil {
+		return fmt.Errorf("tournament %s match %s: %w", t.id, m.ID, err)
+	}
+
+	winner, finished := g.Winner()
+	if !finished {
+		return nil
+	}
+
+	// The players swap sides every game
+	slot := int(winner)
+	if (len(m.GameIDs)-1)%2 == 1 {
+		slot = 1 - slot
+	}
+
+	m.Wins[slot]++
+	m.current = ""
+
+	if m.Wins[slot] > t.opts.BestOf/2 {
+		t.decide(i, slot)
+	}
+
+	return nil
+}
+
+// createGame creates the next game of a match in the manager.
+func (t *Tournament) createGame(i int) (TournamentGame, error) {
+	m := &t.matches[i]
+	id := fmt.Sprintf("%s-%s-%d", t.id, m.ID, len(m.GameIDs)+1)
+
+	players := Players{m.Players[0], m.Players[1]}
+	if len(m.GameIDs)%2 == 1 {
+		players = Players{m.Players[1], m.Players[0]}
+	}
+
+	err := t.manager.Create(id)
+	if err != nil {
+		return TournamentGame{}, fmt.Errorf("tournament %s match %s: %w", t.id, m.ID, err)
+	}
+
+	var tokens [2]string
+	err = t.manager.SetPlayers(id, players)
+	if err == nil {
+		err = t.manager.SetRated(id, !t.opts.Unrated)
+	}
+	if err == nil {
+		tokens, err = t.manager.IssueTokens(id)
+	}
+	if err != nil {
+		// The match has no game yet so it is created again the next time the tournament advances
+		_ = t.manager.Remove(id)
+		return TournamentGame{}, fmt.Errorf("tournament %s match %s: %w", t.id, m.ID, err)
+	}
+
+	m.GameIDs = append(m.GameIDs, id)
+	m.current = id
+
+	return TournamentGame{MatchID: m.ID, GameID: id, Players: players, Tokens: tokens}, nil
+}
+
+// Entrants returns the players in seed order.
+func (t *Tournament) Entrants() []Entrant {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]Entrant(nil), t.entrants...)
+}
+
+// Matches returns every match created so far. Elimination tournaments create every match up
+// front, Swiss tournaments create the matches of each round once the previous round is done.
+func (t *Tournament) Matches() []TournamentMatch {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.publicMatches()
+}
+
+func (t *Tournament) publicMatches() []TournamentMatch {
+	matches := make([]TournamentMatch, 0, len(t.matches))
+	for _, m := range t.matches {
+		public := m.TournamentMatch
+		public.GameIDs = append([]string(nil), m.GameIDs...)
+		matches = append(matches, public)
+	}
+
+	return matches
+}
+
+// Finished reports whether every match has been decided.
+func (t *Tournament) Finished() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.finished()
+}
+
+func (t *Tournament) finished() bool {
+	for _, m := range t.matches {
+		if !m.done {
+			return false
+		}
+	}
+
+	return t.opts.Format != Swiss || t.lastRound() >= t.rounds
+}
+
+// Champion returns the winner of the tournament once it has finished.
+func (t *Tournament) Champion() (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.finished() {
+		return "", false
+	}
+
+	return t.standings()[0].Handle, true
+}
+
+// Standing is the position of a player in a tournament. Points are the number of matches
+// won, with a bye counting as a win in Swiss tournaments.
+type Standing struct {
+	Rank        int     `json:"rank"`
+	Handle      string  `json:"handle"`
+	Seed        int     `json:"seed"`
+	Points      float64 `json:"points"`
+	MatchWins   int     `json:"matchWins"`
+	MatchLosses int     `json:"matchLosses"`
+	GameWins    int     `json:"gameWins"`
+	GameLosses  int     `json:"gameLosses"`
+	// Buchholz is the sum of the points of every opponent, the first tiebreak.
+	Buchholz float64 `json:"buchholz"`
+	// SonnebornBerger is the sum of the points of every opponent beaten, the second tiebreak.
+	SonnebornBerger float64 `json:"sonnebornBerger"`
+	// Eliminated is true once a player is knocked out of an elimination tournament.
+	Eliminated bool `json:"eliminated"`
+	// eliminatedAt is the stage of the match the player was knocked out in
+	eliminatedAt int
+}
+
+// Standings returns every player from first to last. Swiss and round robin tournaments are
+// ranked by points, then Buchholz, then Sonneborn-Berger, then games won minus games lost
+// and finally seed. Elimination tournaments rank the players still in first, then the
+// players knocked out last, then by the same tiebreaks.
+func (t *Tournament) Standings() []Standing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.standings()
+}
+
+func (t *Tournament) standings() []Standing {
+	index := map[string]int{}
+	standings := make([]Standing, len(t.entrants))
+	for i, e := range t.entrants {
+		index[e.Handle] = i
+		standings[i] = Standing{Handle: e.Handle, Seed: e.Seed}
+	}
+
+	lossesToEliminate := 1
+	if t.opts.Format == DoubleElimination {
+		lossesToEliminate = 2
+	}
+
+	var opponents [][2]int
+	for _, m := range t.matches {
+		if !m.done {
+			continue
+		}
+
+		if m.Bye {
+			if m.Winner != "" && t.opts.Format == Swiss {
+				standings[index[m.Winner]].Points++
+			}
+			continue
+		}
+
+		w, l := index[m.Players[0]], index[m.Players[1]]
+		if m.Winner == m.Players[1] {
+			w, l = l, w
+		}
+
+		winner, loser := &standings[w], &standings[l]
+		winner.MatchWins++
+		winner.Points++
+		loser.MatchLosses++
+		if loser.MatchLosses == lossesToEliminate && (t.opts.Format == SingleElimination || t.opts.Format == DoubleElimination) {
+			loser.Eliminated = true
+			loser.eliminatedAt = m.stage
+		}
+
+		for slot, player := range m.Players {
+			s := &standings[index[player]]
+			s.GameWins += m.Wins[slot]
+			s.GameLosses += m.Wins[1-slot]
+		}
+
+		opponents = append(opponents, [2]int{w, l})
+	}
+
+	for _, pair := range opponents {
+		w, l := pair[0], pair[1]
+		standings[w].Buchholz += standings[l].Points
+		standings[l].Buchholz += standings[w].Points
+		standings[w].SonnebornBerger += standings[l].Points
+	}
+
+	sort.Slice(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Eliminated != b.Eliminated {
+			return !a.Eliminated
+		}
+
+		if a.eliminatedAt != b.eliminatedAt {
+			return a.eliminatedAt > b.eliminatedAt
+		}
+
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+
+		if a.Buchholz != b.Buchholz {
+			return a.Buchholz > b.Buchholz
+		}
+
+		if a.SonnebornBerger != b.SonnebornBerger {
+			return a.SonnebornBerger > b.SonnebornBerger
+		}
+
+		if a.GameWins-a.GameLosses != b.GameWins-b.GameLosses {
+			return a.GameWins-a.GameLosses > b.GameWins-b.GameLosses
+		}
+
+		return a.Seed < b.Seed
+	})
+
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+
+	return standings
+}
@@ -0,0 +1,137 @@
+package twittership
+
+// ShipView describes a single ship as seen by one side of a game.
+type ShipView struct {
+	Name string `json:"name"`
+	// Position is the ship position in the same format used to load ships I.E. A1H.
+	Position string `json:"position"`
+	Size     int    `json:"size"`
+	Hits     int    `json:"hits"`
+	Sunk     bool   `json:"sunk"`
+}
+
+// BoardView describes a single board as seen by one side of a game.
+type BoardView struct {
+	// Ships are the ships on the board that the viewing side is allowed to see.
+	Ships []ShipView `json:"ships"`
+	// Shots are the volleys that have been fired at the board.
+	Shots []Move `json:"shots"`
+}
+
+// PlayerView is a redacted view of a game for one side. It contains everything that
+// side is allowed to know: their own ships, every volley fired by either side, and
+// only the opponent ships that have already been sunk.
+type PlayerView struct {
+	Side         Side      `json:"side"`
+	Turn         Side      `json:"turn"`
+	Finished     bool      `json:"finished"`
+	Winner       *Side     `json:"winner,omitempty"`
+	FinishReason string    `json:"finishReason,omitempty"`
+	Own          BoardView `json:"own"`
+	Opponent     BoardView `json:"opponent"`
+}
+
+// View returns the redacted view of the game for the side provided.
+func (g Game) View(side Side) PlayerView {
+	p := g.perspective(side)
+
+	view := PlayerView{
+		Side:     side,
+		Turn:     g.Turn(),
+		Finished: g.IsFinished(),
+		Own: BoardView{
+			Ships: shipViews(p.playerShips, false),
+			Shots: []Move{},
+		},
+		Opponent: BoardView{
+			Ships: shipViews(p.enemyShips, true),
+			Shots: []Move{},
+		},
+	}
+
+	if winner, ok := g.Winner(); ok {
+		view.Winner = &winner
+		view.FinishReason = g.FinishReason().String()
+	}
+
+	for _, m := range g.Moves() {
+		if m.Side == side {
+			view.Opponent.Shots = append(view.Opponent.Shots, m)
+		} else {
+			view.Own.Shots = append(view.Own.Shots, m)
+		}
+	}
+
+	return view
+}
+
+// SpectatorView is a view of a game for someone who isn't playing it. Both boards only show
+// what has been revealed: every volley fired at them and the ships that have been sunk.
+type SpectatorView struct {
+	Turn         Side      `json:"turn"`
+	Finished     bool      `json:"finished"`
+	Winner       *Side     `json:"winner,omitempty"`
+	FinishReason string    `json:"finishReason,omitempty"`
+	Player       BoardView `json:"player"`
+	Enemy        BoardView `json:"enemy"`
+}
+
+// SpectatorView returns the view of the game for spectators.
+func (g Game) SpectatorView() SpectatorView {
+	view := SpectatorView{
+		Turn:     g.Turn(),
+		Finished: g.IsFinished(),
+		Player: BoardView{
+			Ships: shipViews(g.playerShips, true),
+			Shots: []Move{},
+		},
+		Enemy: BoardView{
+			Ships: shipViews(g.enemyShips, true),
+			Shots: []Move{},
+		},
+	}
+
+	if winner, ok := g.Winner(); ok {
+		view.Winner = &winner
+		view.FinishReason = g.FinishReason().String()
+	}
+
+	for _, m := range g.Moves() {
+		if m.Side == PlayerSide {
+			view.Enemy.Shots = append(view.Enemy.Shots, m)
+		} else {
+			view.Player.Shots = append(view.Player.Shots, m)
+		}
+	}
+
+	return view
+}
+
+func shipViews(ships []ship, sunkOnly bool) []ShipView {
+	views := []ShipView{}
+
+	for _, s := range ships {
+		sunk := s.hits >= s.width
+		if sunkOnly && !sunk {
+			continue
+		}
+
+		views = append(views, ShipView{
+			Name:     s.shipType.String(),
+			Position: shipPositionString(s),
+			Size:     s.width,
+			Hits:     s.hits,
+			Sunk:     sunk,
+		})
+	}
+
+	return views
+}
+
+func shipPositionString(s ship) string {
+	if s.direction == vertical {
+		return positionString(s.x, s.y) + "V"
+	}
+
+	return positionString(s.x, s.y) + "H"
+}
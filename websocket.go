@@ -0,0 +1,331 @@
+package twittership
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// liveSendBuffer is how many events may be queued for a client before it is
+	// considered too slow and disconnected.
+	liveSendBuffer = 64
+	liveWriteWait  = 10 * time.Second
+	livePongWait   = 60 * time.Second
+	livePingPeriod = livePongWait * 9 / 10
+	liveMaxMessage = 1024
+)
+
+// LiveEvent is pushed to every websocket client watching a game.
+type LiveEvent struct {
+	// Type is one of "shot", "result", "sunk", "gameOver", "turn", or "error".
+	Type   string `json:"type"`
+	Side   *Side  `json:"side,omitempty"`
+	Move   *Move  `json:"move,omitempty"`
+	Result string `json:"result,omitempty"`
+	Ship   string `json:"ship,omitempty"`
+	Winner *Side  `json:"winner,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// liveCommand is sent by a player over a websocket to act in a game.
+type liveCommand struct {
+	// Type is either "ships" or "volley".
+	Type      string `json:"type"`
+	Positions string `json:"positions"`
+	Position  string `json:"position"`
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+type liveClient struct {
+	conn   *websocket.Conn
+	send   chan []byte
+	side   Side
+	player bool
+	mu     sync.Mutex
+	closed bool
+}
+
+// trySend queues a message for the client without blocking. It returns false if the
+// queue is full or the client has already been closed.
+func (c *liveClient) trySend(message []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+
+	select {
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *liveClient) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// liveHub keeps track of every client watching a single game.
+type liveHub struct {
+	mu      sync.Mutex
+	clients map[*liveClient]struct{}
+}
+
+func (h *liveHub) add(c *liveClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.clients[c] = struct{}{}
+}
+
+func (h *liveHub) remove(c *liveClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients, c)
+	c.close()
+}
+
+// broadcast queues a message for every client without blocking. Clients whose queue
+// is full are too slow to keep up so they are disconnected rather than holding up
+// the game for everybody else.
+func (h *liveHub) broadcast(message []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.clients {
+		if !c.trySend(message) {
+			delete(h.clients, c)
+			c.close()
+		}
+	}
+}
+
+// join adds a client to the hub of the game, creating the hub for the first client.
+func (s *Server) join(id string, c *liveClient) *liveHub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, ok := s.hubs[id]
+	if !ok {
+		h = &liveHub{clients: map[*liveClient]struct{}{}}
+		s.hubs[id] = h
+	}
+
+	h.add(c)
+
+	return h
+}
+
+// leave removes a client from the hub of the game and drops the hub once nobody is
+// watching, so games that are no longer watched don't keep their hub.
+func (s *Server) leave(id string, h *liveHub, c *liveClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h.remove(c)
+
+	h.mu.Lock()
+	empty := len(h.clients) == 0
+	h.mu.Unlock()
+
+	if empty && s.hubs[id] == h {
+		delete(s.hubs, id)
+	}
+}
+
+// publish sends events to every client watching the game. It never blocks so it is
+// safe to call from a game subscriber while the game is locked, which keeps the events
+// in the order the moves were made.
+func (s *Server) publish(id string, events ...LiveEvent) {
+	s.mu.RLock()
+	h, ok := s.hubs[id]
+	s.mu.RUnlock()
+
+	if !ok {
+		return
+	}
+
+	for _, event := range events {
+		message, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+
+		h.broadcast(message)
+
+		if event.Type == "gameOver" {
+			s.closeHub(id, h)
+		}
+	}
+}
+
+// closeHub disconnects every client once the game is over. Each client is sent the events
+// already queued for it before being disconnected.
+func (s *Server) closeHub(id string, h *liveHub) {
+	s.mu.Lock()
+	if s.hubs[id] == h {
+		delete(s.hubs, id)
+	}
+	s.mu.Unlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.clients {
+		delete(h.clients, c)
+		c.close()
+	}
+}
+
+// liveEventFor converts a game event to the event sent to websocket clients. Ship
+// placements are never sent as they would reveal where the ships are.
+func liveEventFor(e Event) (LiveEvent, bool) {
+	switch e := e.(type) {
+	case ShotFiredEvent:
+		return LiveEvent{Type: "shot", Side: &e.Move.Side, Move: &e.Move}, true
+	case HitEvent:
+		return LiveEvent{Type: "result", Side: &e.Move.Side, Move: &e.Move, Result: e.Move.Result()}, true
+	case MissEvent:
+		return LiveEvent{Type: "result", Side: &e.Move.Side, Move: &e.Move, Result: e.Move.Result()}, true
+	case SunkEvent:
+		return LiveEvent{Type: "sunk", Side: &e.Move.Side, Move: &e.Move, Ship: e.Ship}, true
+	case GameOverEvent:
+		return LiveEvent{Type: "gameOver", Winner: &e.Winner, Reason: e.Reason.String()}, true
+	case TurnChangedEvent:
+		return LiveEvent{Type: "turn", Side: &e.Turn}, true
+	default:
+		return LiveEvent{}, false
+	}
+}
+
+// serveLive upgrades the request to a websocket that receives every event for the game.
+// Requests with a valid token, either as a "token" query parameter or a bearer token,
+// may also act for their side. Requests without a token join as spectators.
+func (s *Server) serveLive(w http.ResponseWriter, r *http.Request, id string) {
+	tokens, err := s.manager.gameTokens(id)
+	if err != nil {
+		writeManagerError(w, err)
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = bearerToken(r)
+	}
+
+	client := &liveClient{send: make(chan []byte, liveSendBuffer)}
+	if token != "" {
+		side, err := sideForToken(tokens, token)
+		if err != nil {
+			writeManagerError(w, err)
+			return
+		}
+
+		client.side, client.player = side, true
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied to the client with an error
+		return
+	}
+
+	client.conn = conn
+	h := s.join(id, client)
+
+	go client.writeEvents()
+	s.readCommands(id, h, client)
+}
+
+func (s *Server) readCommands(id string, h *liveHub, c *liveClient) {
+	defer s.leave(id, h, c)
+
+	c.conn.SetReadLimit(liveMaxMessage)
+	_ = c.conn.SetReadDeadline(time.Now().Add(livePongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(livePongWait))
+	})
+
+	for {
+		var cmd liveCommand
+		err := c.conn.ReadJSON(&cmd)
+		if err != nil {
+			return
+		}
+
+		err = s.handleCommand(id, c, cmd)
+		if err != nil {
+			reply, _ := json.Marshal(LiveEvent{Type: "error", Error: err.Error()})
+			c.trySend(reply)
+		}
+	}
+}
+
+func (s *Server) handleCommand(id string, c *liveClient, cmd liveCommand) error {
+	if !c.player {
+		return fmt.Errorf("spectators cannot act in a game")
+	}
+
+	switch cmd.Type {
+	case "ships":
+		return s.manager.Do(id, func(g *Game) error {
+			return placeShipsForSide(g, c.side, cmd.Positions)
+		})
+	case "volley":
+		return s.manager.Do(id, func(g *Game) error {
+			_, err := volleyForSide(g, c.side, cmd.Position)
+			return err
+		})
+	default:
+		return fmt.Errorf("unknown command type: %s", cmd.Type)
+	}
+}
+
+func (c *liveClient) writeEvents() {
+	ticker := time.NewTicker(livePingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+			if !ok {
+				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+
+			err := c.conn.WriteMessage(websocket.TextMessage, message)
+			if err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+			err := c.conn.WriteMessage(websocket.PingMessage, nil)
+			if err != nil {
+				return
+			}
+		}
+	}
+}